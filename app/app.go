@@ -1,38 +1,115 @@
 package app
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"golang.org/x/net/context"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/docker/libcompose/cli/logger"
+	"github.com/pkg/errors"
 	"github.com/rancher/rancher-compose-executor/lookup"
 	"github.com/rancher/rancher-compose-executor/project"
 	"github.com/rancher/rancher-compose-executor/project/options"
 	"github.com/rancher/rancher-compose-executor/rancher"
+	"github.com/rancher/rancher-compose-executor/yaml"
 	"github.com/urfave/cli"
 )
 
 type RancherProjectFactory struct {
 }
 
+// How long up waits for a second signal once it detached from the logs.
+const detachGracePeriod = 2 * time.Second
+
+// Commands that only read from the server, the stack is not created for them
+// if it does not exist yet.
+var readOnlyCommands = map[string]bool{
+	"build": true,
+	"exec":  true,
+	"logs":  true,
+	"plan":  true,
+	"ps":    true,
+}
+
+// Commands that only act on the services of an existing stack, they fail
+// instead of creating the stack when it does not exist.
+var existingStackCommands = map[string]bool{
+	"down":    true,
+	"exec":    true,
+	"logs":    true,
+	"pull":    true,
+	"restart": true,
+	"rm":      true,
+	"scale":   true,
+	"start":   true,
+	"stop":    true,
+}
+
+// Commands that only work on the compose files and never talk to the server.
+var offlineCommands = map[string]bool{
+	"config": true,
+}
+
 func (p *RancherProjectFactory) Create(c *cli.Context) (*project.Project, error) {
+	context, err := newContext(c)
+	if err != nil {
+		return nil, err
+	}
+
+	if offlineCommands[c.Command.Name] {
+		p := project.NewProject(&context.Context)
+		if err := p.Parse(); err != nil {
+			return nil, err
+		}
+		return p, nil
+	}
+
+	return rancher.NewProject(context)
+}
+
+func newContext(c *cli.Context) (*rancher.Context, error) {
+	// The flags already hold the environment variables, the profile only
+	// fills in what neither gave.
+	profile, err := loadProfile(profilesFile(), c.GlobalString("profile"))
+	if err != nil {
+		return nil, err
+	}
+	if !usesProfile(c.GlobalString("url"), profile) {
+		logrus.Debugf("Not using profile %s for %s", profile.name, c.GlobalString("url"))
+		profile = &Profile{}
+	} else if profile.name != "" && c.GlobalString("profile") == "" {
+		logrus.Infof("Using the default profile %s", profile.name)
+	}
+
 	context := &rancher.Context{
 		Context: project.Context{
 			ResourceLookup: &lookup.FileResourceLookup{},
 			LoggerFactory:  logger.NewColorLoggerFactory(),
 		},
-		Url:        c.GlobalString("url"),
-		AccessKey:  c.GlobalString("access-key"),
-		SecretKey:  c.GlobalString("secret-key"),
-		PullCached: c.Bool("cached"),
-		Uploader:   &rancher.S3Uploader{},
-		Args:       c.Args(),
+		Url:                firstSet(c.GlobalString("url"), profile.URL),
+		AccessKey:          firstSet(c.GlobalString("access-key"), profile.AccessKey),
+		SecretKey:          firstSet(c.GlobalString("secret-key"), profile.SecretKey),
+		Environment:        firstSet(c.GlobalString("environment"), profile.Environment),
+		CACert:             firstSet(c.GlobalString("ca-cert"), profile.CACert),
+		InsecureSkipVerify: c.GlobalBool("insecure-skip-verify") || profile.InsecureSkipVerify,
+		Proxy:              c.GlobalString("proxy"),
+		APITimeout:         c.GlobalDuration("api-timeout"),
+		PullCached:         c.Bool("cached"),
+		PullConcurrency:    c.Int("parallel"),
+		Uploader:           &rancher.S3Uploader{},
+		Args:               c.Args(),
+	}
+
+	if output := outputFor(c); output != nil {
+		context.LoggerFactory = output
 	}
 
 	Populate(&context.Context, c)
@@ -43,7 +120,14 @@ func (p *RancherProjectFactory) Create(c *cli.Context) (*project.Project, error)
 		return nil, err
 	}
 
-	qLookup, err := lookup.NewQuestionLookup(rancherComposeFile, &lookup.OsEnvLookup{})
+	answers := map[string]string{}
+	if answersFile := c.GlobalString("answers"); answersFile != "" {
+		if answers, err = lookup.ParseAnswers(answersFile); err != nil {
+			return nil, err
+		}
+	}
+
+	qLookup, err := lookup.NewQuestionLookup(rancherComposeFile, answers, !c.GlobalBool("no-prompt"), &lookup.OsEnvLookup{})
 	if err != nil {
 		return nil, err
 	}
@@ -63,8 +147,10 @@ func (p *RancherProjectFactory) Create(c *cli.Context) (*project.Project, error)
 	context.Interval = int64(c.Int("interval"))
 	context.ConfirmUpgrade = c.Bool("confirm-upgrade")
 	context.Pull = c.Bool("pull")
+	context.ReadOnly = readOnlyCommands[c.Command.Name]
+	context.ExistingStack = existingStackCommands[c.Command.Name]
 
-	return rancher.NewProject(context)
+	return context, nil
 }
 
 func resolveRancherCompose(composeFile, rancherComposeFile string) (string, error) {
@@ -101,13 +187,19 @@ func Populate(context *project.Context, c *cli.Context) {
 type ProjectAction func(project *project.Project, c *cli.Context) error
 
 func WithProject(factory ProjectFactory, action ProjectAction) func(context *cli.Context) error {
-	return func(context *cli.Context) error {
+	return withOutput(func(context *cli.Context) error {
 		p, err := factory.Create(context)
 		if err != nil {
-			logrus.Fatalf("Failed to read project: %v", err)
+			return &validationError{"Failed to read project: ", err}
 		}
+
+		if output := outputFor(context); output != nil {
+			stop := output.listen(p)
+			defer stop()
+		}
+
 		return action(p, context)
-	}
+	})
 }
 
 func UpCommand(factory ProjectFactory) cli.Command {
@@ -124,6 +216,10 @@ func UpCommand(factory ProjectFactory) cli.Command {
 				Name:  "d",
 				Usage: "Do not block and log",
 			},
+			cli.BoolFlag{
+				Name:  "abort-on-exit",
+				Usage: "Stop the services when interrupted instead of only detaching from the logs",
+			},
 			cli.BoolFlag{
 				Name:  "render",
 				Usage: "Display processed Compose files and exit",
@@ -154,6 +250,11 @@ func UpCommand(factory ProjectFactory) cli.Command {
 				Usage: "Update interval in milliseconds",
 				Value: 1000,
 			},
+			cli.StringSliceFlag{
+				Name:  "scale",
+				Usage: "Scale SERVICE to NUM instances, overriding the scale in the compose file (SERVICE=NUM)",
+				Value: &cli.StringSlice{},
+			},
 		},
 	}
 }
@@ -166,44 +267,506 @@ func CreateCommand(factory ProjectFactory) cli.Command {
 	}
 }
 
+func BuildCommand(factory ProjectFactory) cli.Command {
+	return cli.Command{
+		Name:   "build",
+		Usage:  "Upload the build contexts of services and print the resulting images",
+		Action: WithProject(factory, ProjectBuild),
+		Flags: []cli.Flag{
+			cli.BoolFlag{
+				Name:  "no-cache",
+				Usage: "Upload the build context even if it was uploaded before",
+			},
+			cli.BoolFlag{
+				Name:  "pull",
+				Usage: "Pull the base images on the hosts first, so that the images are built on their latest versions",
+			},
+		},
+	}
+}
+
+func RemoveCommand(factory ProjectFactory) cli.Command {
+	return cli.Command{
+		Name:   "rm",
+		Usage:  "Delete services",
+		Action: WithProject(factory, ProjectDelete),
+		Flags: []cli.Flag{
+			cli.BoolFlag{
+				Name:  "force, f",
+				Usage: "Allow deletion of running services",
+			},
+			cli.BoolFlag{
+				Name:  "volumes, v",
+				Usage: "Remove the volumes of the stack when all services are deleted",
+			},
+		},
+	}
+}
+
+func DownCommand(factory ProjectFactory) cli.Command {
+	return cli.Command{
+		Name:   "down",
+		Usage:  "Stop and remove services and, optionally, volumes",
+		Action: WithProject(factory, ProjectDown),
+		Flags: []cli.Flag{
+			cli.BoolFlag{
+				Name:  "volumes, v",
+				Usage: "Remove the volumes declared in the compose file",
+			},
+			cli.BoolFlag{
+				Name:  "remove-orphans",
+				Usage: "Remove services of the stack not defined in the compose file",
+			},
+		},
+	}
+}
+
+func StartCommand(factory ProjectFactory) cli.Command {
+	return cli.Command{
+		Name:   "start",
+		Usage:  "Start services",
+		Action: WithProject(factory, ProjectStart),
+	}
+}
+
+func StopCommand(factory ProjectFactory) cli.Command {
+	return cli.Command{
+		Name:   "stop",
+		Usage:  "Stop services",
+		Action: WithProject(factory, ProjectStop),
+	}
+}
+
+func RestartCommand(factory ProjectFactory) cli.Command {
+	return cli.Command{
+		Name:   "restart",
+		Usage:  "Restart services",
+		Action: WithProject(factory, ProjectRestart),
+		Flags: []cli.Flag{
+			cli.IntFlag{
+				Name:  "batch-size",
+				Usage: "Number of containers to restart at once",
+				Value: 1,
+			},
+			cli.IntFlag{
+				Name:  "interval",
+				Usage: "Restart interval in milliseconds",
+				Value: 0,
+			},
+		},
+	}
+}
+
+func ScaleCommand(factory ProjectFactory) cli.Command {
+	return cli.Command{
+		Name:      "scale",
+		Usage:     "Scale services",
+		ArgsUsage: "SERVICE=NUM...",
+		Action:    WithProject(factory, ProjectScale),
+	}
+}
+
+func LogsCommand(factory ProjectFactory) cli.Command {
+	return cli.Command{
+		Name:   "logs",
+		Usage:  "Get service logs",
+		Action: WithProject(factory, ProjectLog),
+		Flags: []cli.Flag{
+			cli.BoolFlag{
+				Name:  "follow, f",
+				Usage: "Follow log output",
+			},
+			cli.IntFlag{
+				Name:  "tail",
+				Usage: "Number of lines to show from the end of the logs of each container",
+			},
+			cli.StringFlag{
+				Name:  "since",
+				Usage: "Show logs since a timestamp (RFC3339 or UNIX) or relative duration (e.g. 10m), filtered on the timestamps the host puts in front of each line",
+			},
+			cli.BoolFlag{
+				Name:  "timestamps, t",
+				Usage: "Show timestamps",
+			},
+		},
+	}
+}
+
+func PsCommand(factory ProjectFactory) cli.Command {
+	return cli.Command{
+		Name:   "ps",
+		Usage:  "List services and containers",
+		Action: WithProject(factory, ProjectPs),
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "format",
+				Usage: "Output format (table or json)",
+				Value: "table",
+			},
+		},
+	}
+}
+
+func PullCommand(factory ProjectFactory) cli.Command {
+	return cli.Command{
+		Name:   "pull",
+		Usage:  "Pulls images for services",
+		Action: WithProject(factory, ProjectPull),
+		Flags: []cli.Flag{
+			cli.BoolFlag{
+				Name:  "cached, c",
+				Usage: "Only update hosts that have the image cached, don't pull new",
+			},
+			cli.IntFlag{
+				Name:  "parallel",
+				Usage: "Number of images to pull at once",
+				Value: 4,
+			},
+		},
+	}
+}
+
+func PlanCommand(factory ProjectFactory) cli.Command {
+	return cli.Command{
+		Name:    "plan",
+		Aliases: []string{"diff"},
+		Usage:   "Show what up would change without changing anything",
+		Action:  WithProject(factory, ProjectPlan),
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "format",
+				Usage: "Output format (text or json)",
+				Value: "text",
+			},
+			cli.BoolFlag{
+				Name:  "exit-code",
+				Usage: "Exit with status 2 if up would change anything",
+			},
+			cli.BoolFlag{
+				Name:  "upgrade, u",
+				Usage: "Plan for up --upgrade",
+			},
+			cli.BoolFlag{
+				Name:  "force-upgrade",
+				Usage: "Plan for up --force-upgrade",
+			},
+		},
+	}
+}
+
 func ProjectCreate(p *project.Project, c *cli.Context) error {
-	if err := p.Create(context.Background(), options.Create{}, c.Args()...); err != nil {
+	return withProgress(p, c, func() error {
+		if err := p.Create(context.Background(), options.Create{}, c.Args()...); err != nil {
+			return err
+		}
+
+		// This is to fix circular links... What!? It works.
+		return p.Create(context.Background(), options.Create{}, c.Args()...)
+	})
+}
+
+func ProjectBuild(p *project.Project, c *cli.Context) error {
+	buildOptions := options.Build{
+		NoCache: c.Bool("no-cache"),
+		Pull:    c.Bool("pull"),
+	}
+
+	// The images are part of the events in JSON mode
+	if outputFor(c) != nil {
+		return p.Build(context.Background(), buildOptions, c.Args()...)
+	}
+
+	results := newBuildResults()
+
+	p.AddListener(project.NewDefaultListener(p))
+	p.AddListener(results.listener)
+
+	err := p.Build(context.Background(), buildOptions, c.Args()...)
+	results.close()
+
+	fmt.Print(results.String())
+	return err
+}
+
+func ProjectDelete(p *project.Project, c *cli.Context) error {
+	return p.Delete(context.Background(), options.Delete{
+		RemoveVolume:  c.Bool("volumes"),
+		RemoveRunning: c.Bool("force"),
+	}, c.Args()...)
+}
+
+func ProjectDown(p *project.Project, c *cli.Context) error {
+	if len(c.Args()) > 0 {
+		return fmt.Errorf("down does not accept service names, use rm to delete services")
+	}
+
+	return p.Down(context.Background(), options.Down{
+		RemoveVolume:  c.Bool("volumes"),
+		RemoveOrphans: c.Bool("remove-orphans"),
+	})
+}
+
+func ProjectStart(p *project.Project, c *cli.Context) error {
+	return p.Start(context.Background(), c.Args()...)
+}
+
+func ProjectStop(p *project.Project, c *cli.Context) error {
+	return p.Stop(context.Background(), c.Args()...)
+}
+
+func ProjectRestart(p *project.Project, c *cli.Context) error {
+	return p.Restart(context.Background(), c.Args()...)
+}
+
+func ProjectScale(p *project.Project, c *cli.Context) error {
+	servicesScale, err := parseScale(c.Args())
+	if err != nil {
+		return err
+	}
+
+	if len(servicesScale) == 0 {
+		return fmt.Errorf("No services to scale, expected SERVICE=NUM")
+	}
+
+	return p.Scale(context.Background(), servicesScale)
+}
+
+func parseScale(args []string) (map[string]int, error) {
+	servicesScale := map[string]int{}
+	for _, arg := range args {
+		parts := strings.SplitN(arg, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("Invalid scale parameter: %s, expected SERVICE=NUM", arg)
+		}
+
+		count, err := strconv.Atoi(parts[1])
+		if err != nil || count < 0 {
+			return nil, fmt.Errorf("Invalid scale for %s: %s", parts[0], parts[1])
+		}
+
+		servicesScale[parts[0]] = count
+	}
+	return servicesScale, nil
+}
+
+// selected tells if a service is part of the services given on the command
+// line, no services means all of them.
+func selected(services []string, name string) bool {
+	if len(services) == 0 {
+		return true
+	}
+	for _, service := range services {
+		if service == name {
+			return true
+		}
+	}
+	return false
+}
+
+func ProjectPull(p *project.Project, c *cli.Context) error {
+	results := newPullResults()
+
+	// Keep logging progress while the results are being collected
+	output := outputFor(c)
+	if output == nil {
+		p.AddListener(project.NewDefaultListener(p))
+	}
+	p.AddListener(results.listener)
+
+	err := p.Pull(context.Background(), c.Args()...)
+	results.close()
+
+	if output != nil {
+		output.result(results.images)
+	} else {
+		fmt.Print(results.String())
+	}
+	return err
+}
+
+func ProjectPs(p *project.Project, c *cli.Context) error {
+	allInfo, err := p.Ps(context.Background(), c.Args()...)
+	if err != nil {
 		return err
 	}
 
-	// This is to fix circular links... What!? It works.
-	if err := p.Create(context.Background(), options.Create{}, c.Args()...); err != nil {
+	if output := outputFor(c); output != nil {
+		output.result(allInfo)
+		return nil
+	}
+
+	switch c.String("format") {
+	case "json":
+		return json.NewEncoder(os.Stdout).Encode(allInfo)
+	case "table":
+		columns := []string{"Name", "Type", "State", "Health", "Scale", "Host", "Ports", "Sync"}
+		fmt.Print(allInfo.String(columns, true))
+		return nil
+	default:
+		return fmt.Errorf("Invalid format %s, expected table or json", c.String("format"))
+	}
+}
+
+func ProjectPlan(p *project.Project, c *cli.Context) error {
+	plan, err := p.Plan(context.Background(), c.Args()...)
+	if err != nil {
 		return err
 	}
 
+	if output := outputFor(c); output != nil {
+		output.result(plan)
+	} else {
+		switch c.String("format") {
+		case "json":
+			if err := json.NewEncoder(os.Stdout).Encode(plan); err != nil {
+				return err
+			}
+		case "text":
+			fmt.Print(plan.String())
+		default:
+			return fmt.Errorf("Invalid format %s, expected text or json", c.String("format"))
+		}
+	}
+
+	if c.Bool("exit-code") && plan.HasChanges() {
+		return cli.NewExitError("", ExitCodeChanges)
+	}
 	return nil
 }
 
+func ProjectLog(p *project.Project, c *cli.Context) error {
+	since, err := parseSince(c.String("since"), time.Now())
+	if err != nil {
+		return err
+	}
+
+	// The host applies the tail before the lines can be filtered by time,
+	// together they would silently drop the lines asked for.
+	tail := -1
+	if c.IsSet("tail") {
+		if !since.IsZero() {
+			return fmt.Errorf("The --since and --tail options can not be used together")
+		}
+		tail = c.Int("tail")
+	}
+
+	return p.Log(context.Background(), options.Log{
+		Follow:     c.Bool("follow"),
+		Tail:       tail,
+		Since:      since,
+		Timestamps: c.Bool("timestamps"),
+	}, c.Args()...)
+}
+
+func parseSince(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t, nil
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0), nil
+	}
+	return time.Time{}, fmt.Errorf("Invalid value for --since: %s", value)
+}
+
 func ProjectUp(p *project.Project, c *cli.Context) error {
 	if c.Bool("render") {
 		renderedComposeBytes, err := p.Render()
 		if err != nil {
 			return err
 		}
+		if output := outputFor(c); output != nil {
+			rendered := []string{}
+			for _, contents := range renderedComposeBytes {
+				rendered = append(rendered, string(contents))
+			}
+			output.result(rendered)
+			return nil
+		}
 		for _, contents := range renderedComposeBytes {
 			fmt.Println(string(contents))
 		}
 		return nil
 	}
 
-	if err := p.Create(context.Background(), options.Create{}, c.Args()...); err != nil {
-		return err
+	if c.Bool("d") && c.Bool("abort-on-exit") {
+		return fmt.Errorf("The -d and --abort-on-exit options can not be used together")
 	}
 
-	if err := p.Up(context.Background(), options.Up{}, c.Args()...); err != nil {
+	servicesScale, err := parseScale(c.StringSlice("scale"))
+	if err != nil {
 		return err
 	}
 
-	if !c.Bool("d") {
-		p.Log(context.Background(), true)
-		// wait forever
-		<-make(chan interface{})
+	// New services are created with the requested scale right away, existing
+	// ones are scaled once they are up.
+	for name, count := range servicesScale {
+		serviceConfig, ok := p.ServiceConfigs.Get(name)
+		if !ok {
+			return fmt.Errorf("No such service: %s", name)
+		}
+		if !selected(c.Args(), name) {
+			return fmt.Errorf("Service %s is scaled but not in the services to bring up", name)
+		}
+		serviceConfig.Scale = yaml.StringorInt(count)
 	}
 
-	return nil
+	ctx, interrupts := newInterrupter()
+	defer interrupts.stop()
+
+	upErr := withProgress(p, c, func() error {
+		if err := p.Create(ctx, options.Create{}, c.Args()...); err != nil {
+			return err
+		}
+
+		if err := p.Up(ctx, options.Up{}, c.Args()...); err != nil {
+			return err
+		}
+
+		if len(servicesScale) > 0 {
+			return p.Scale(ctx, servicesScale)
+		}
+		return nil
+	})
+
+	if upErr != nil {
+		if ctx.Err() == nil {
+			return upErr
+		}
+		upErr = errors.Wrap(upErr, "Interrupted before the services were up")
+	}
+	// Detached, an interrupt only cuts create and up short
+	if c.Bool("d") {
+		return upErr
+	}
+
+	// Following the logs ends with the first signal, the services keep
+	// running unless a second signal comes or --abort-on-exit is set. Log
+	// returns right away when no service has containers to follow, up still
+	// waits for the signal then.
+	if upErr == nil {
+		p.Log(ctx, options.Log{Follow: true, Tail: -1})
+		<-ctx.Done()
+	}
+
+	stop := c.Bool("abort-on-exit")
+	if !stop {
+		logrus.Infof("Detaching, interrupt again within %s to stop the services", detachGracePeriod)
+		stop = interrupts.again(detachGracePeriod)
+	}
+
+	if stop {
+		interrupts.stop()
+		logrus.Infof("Stopping services")
+		if err := p.Stop(context.Background(), c.Args()...); err != nil {
+			return err
+		}
+	}
+
+	return upErr
 }
@@ -0,0 +1,28 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseScale(t *testing.T) {
+	scale, err := parseScale([]string{"web=3", "db=0", "web=2"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"web": 2, "db": 0}, scale)
+
+	scale, err = parseScale(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, scale)
+
+	for _, arg := range []string{"web", "web=", "web=x", "web=-1", "web=1.5"} {
+		_, err := parseScale([]string{arg})
+		assert.Error(t, err, arg)
+	}
+}
+
+func TestSelected(t *testing.T) {
+	assert.True(t, selected(nil, "web"))
+	assert.True(t, selected([]string{"db", "web"}, "web"))
+	assert.False(t, selected([]string{"db"}, "web"))
+}
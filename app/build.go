@@ -0,0 +1,60 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/rancher/rancher-compose-executor/project/events"
+)
+
+// buildResults collects the image and the upload URL of every built service
+// from the project events.
+type buildResults struct {
+	listener chan events.Event
+	done     chan struct{}
+	images   map[string]events.Event
+}
+
+func newBuildResults() *buildResults {
+	r := &buildResults{
+		listener: make(chan events.Event),
+		done:     make(chan struct{}),
+		images:   map[string]events.Event{},
+	}
+	go r.collect()
+	return r
+}
+
+func (r *buildResults) collect() {
+	defer close(r.done)
+	for event := range r.listener {
+		if event.EventType != events.ServiceBuild || event.Data["image"] == "" {
+			continue
+		}
+		r.images[event.ServiceName] = event
+	}
+}
+
+// close must only be called once the project stopped sending events, it waits
+// for every received event to be collected.
+func (r *buildResults) close() {
+	close(r.listener)
+	<-r.done
+}
+
+// String renders one line per service with its image and upload URL.
+func (r *buildResults) String() string {
+	names := []string{}
+	for name := range r.images {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	buffer := &bytes.Buffer{}
+	for _, name := range names {
+		event := r.images[name]
+		fmt.Fprintf(buffer, "%s\t%s\t%s\n", name, event.Data["image"], event.Data["url"])
+	}
+	return buffer.String()
+}
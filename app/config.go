@@ -0,0 +1,104 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/rancher/rancher-compose-executor/config"
+	"github.com/rancher/rancher-compose-executor/project"
+	"github.com/rancher/rancher-compose-executor/utils"
+	"github.com/urfave/cli"
+	"gopkg.in/yaml.v2"
+)
+
+func ConfigCommand(factory ProjectFactory) cli.Command {
+	return cli.Command{
+		Name:   "config",
+		Usage:  "Validate and print the merged compose files",
+		Action: WithProject(factory, ProjectConfig),
+		Flags: []cli.Flag{
+			cli.BoolFlag{
+				Name:  "services",
+				Usage: "Print the service names, one per line",
+			},
+			cli.BoolFlag{
+				Name:  "volumes",
+				Usage: "Print the volume names, one per line",
+			},
+			cli.StringFlag{
+				Name:  "format",
+				Usage: "Output format (yaml or json)",
+				Value: "yaml",
+			},
+		},
+	}
+}
+
+// mergedConfig is what config prints, the version is always 2 since v1
+// files are converted when they are merged.
+type mergedConfig struct {
+	Version       string `yaml:"version"`
+	config.Config `yaml:",inline"`
+}
+
+func ProjectConfig(p *project.Project, c *cli.Context) error {
+	if c.Bool("services") {
+		printNames(c, p.ServiceConfigs.Keys())
+		return nil
+	}
+
+	if c.Bool("volumes") {
+		names := []string{}
+		for name := range p.VolumeConfigs {
+			names = append(names, name)
+		}
+		printNames(c, names)
+		return nil
+	}
+
+	output, err := yaml.Marshal(&mergedConfig{
+		Version: "2",
+		Config:  *p.Config(),
+	})
+	if err != nil {
+		return err
+	}
+
+	format := c.String("format")
+	if outputFor(c) != nil {
+		format = "json"
+	}
+
+	switch format {
+	case "yaml":
+		fmt.Print(string(output))
+		return nil
+	case "json":
+		var data map[string]interface{}
+		if err := yaml.Unmarshal(output, &data); err != nil {
+			return err
+		}
+		if output := outputFor(c); output != nil {
+			output.result(utils.NestedMapsToMapInterface(data))
+			return nil
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(utils.NestedMapsToMapInterface(data))
+	default:
+		return fmt.Errorf("Invalid format %s, expected yaml or json", c.String("format"))
+	}
+}
+
+func printNames(c *cli.Context, names []string) {
+	sort.Strings(names)
+	if output := outputFor(c); output != nil {
+		output.result(names)
+		return
+	}
+	for _, name := range names {
+		fmt.Println(name)
+	}
+}
@@ -0,0 +1,149 @@
+package app
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/net/context"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/pkg/term"
+	"github.com/rancher/rancher-compose-executor/project"
+	"github.com/rancher/rancher-compose-executor/project/options"
+	"github.com/urfave/cli"
+)
+
+func ExecCommand(factory ProjectFactory) cli.Command {
+	return cli.Command{
+		Name:      "exec",
+		Usage:     "Run a command in a running container of a service",
+		ArgsUsage: "SERVICE COMMAND [ARGS...]",
+		Action:    WithProject(factory, ProjectExec),
+		Flags: []cli.Flag{
+			cli.IntFlag{
+				Name:  "index",
+				Usage: "Index of the container when the service has several, ordered by creation",
+				Value: 1,
+			},
+			cli.BoolFlag{
+				Name:  "T",
+				Usage: "Disable pseudo-tty allocation",
+			},
+			cli.BoolFlag{
+				Name:  "no-shell",
+				Usage: "Run the command without /bin/sh, for images without a shell. The exit code of the command is not known then, nor is the terminal resized",
+			},
+		},
+	}
+}
+
+func ProjectExec(p *project.Project, c *cli.Context) error {
+	if len(c.Args()) < 2 {
+		return fmt.Errorf("Expected a service and a command")
+	}
+
+	execOptions := options.Exec{
+		Index:   c.Int("index"),
+		NoShell: c.Bool("no-shell"),
+		Stdin:   os.Stdin,
+		Stdout:  os.Stdout,
+	}
+
+	output := outputFor(c)
+	if output != nil {
+		execOptions.Stdout = output.Writer("stdout")
+	}
+
+	inFd, inIsTerminal := term.GetFdInfo(os.Stdin)
+	if inIsTerminal && !c.Bool("T") && output == nil {
+		execOptions.Tty = true
+
+		if size, err := term.GetWinsize(inFd); err == nil {
+			execOptions.Width = int(size.Width)
+			execOptions.Height = int(size.Height)
+		}
+
+		state, err := term.SetRawTerminal(inFd)
+		if err != nil {
+			return err
+		}
+		defer term.RestoreTerminal(inFd, state)
+
+		resize, stop := notifyResize(inFd)
+		defer stop()
+		execOptions.Resize = resize
+	}
+
+	// Piped input has an end that the command must see
+	execOptions.BufferStdin = !inIsTerminal
+
+	exitCode, err := p.Exec(context.Background(), c.Args()[0], c.Args()[1:], execOptions)
+	if err != nil {
+		return err
+	}
+	if exitCode == options.ExitCodeUnknown {
+		logrus.Warnf("The exit code of the command is not known with --no-shell")
+		return nil
+	}
+	if exitCode != 0 {
+		return &containerExitError{code: exitCode}
+	}
+	return nil
+}
+
+func RunCommand(factory ProjectFactory) cli.Command {
+	return cli.Command{
+		Name:      "run",
+		Usage:     "Run a one-off container from a service definition",
+		ArgsUsage: "SERVICE [COMMAND] [ARGS...]",
+		Action:    WithProject(factory, ProjectRun),
+		Flags: []cli.Flag{
+			cli.BoolFlag{
+				Name:  "rm",
+				Usage: "Remove the container after it exits",
+			},
+			cli.BoolFlag{
+				Name:  "d",
+				Usage: "Detached mode: run the container in the background and print its name",
+			},
+			cli.BoolFlag{
+				Name:  "service-ports",
+				Usage: "Publish the ports of the service, which the containers of the service usually hold already",
+			},
+		},
+	}
+}
+
+func ProjectRun(p *project.Project, c *cli.Context) error {
+	if len(c.Args()) < 1 {
+		return fmt.Errorf("Expected a service")
+	}
+
+	if c.Bool("d") && c.Bool("rm") {
+		return fmt.Errorf("The --rm and -d options can not be used together")
+	}
+
+	runOptions := options.Run{
+		Detached:     c.Bool("d"),
+		Remove:       c.Bool("rm"),
+		ServicePorts: c.Bool("service-ports"),
+		Stdout:       os.Stdout,
+	}
+	if output := outputFor(c); output != nil {
+		runOptions.Stdout = output.Writer("stdout")
+	}
+
+	// An interrupt cancels ctx, so that run returns and removes the container
+	// with --rm
+	ctx, interrupts := newInterrupter()
+	defer interrupts.stop()
+
+	exitCode, err := p.Run(ctx, c.Args()[0], c.Args()[1:], runOptions)
+	if err != nil {
+		return err
+	}
+	if exitCode != 0 {
+		return &containerExitError{code: exitCode}
+	}
+	return nil
+}
@@ -0,0 +1,375 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/libcompose/logger"
+	"github.com/pkg/errors"
+	"github.com/rancher/go-rancher/v2"
+	"github.com/rancher/rancher-compose-executor/project"
+	"github.com/rancher/rancher-compose-executor/project/events"
+	"github.com/urfave/cli"
+)
+
+// Exit codes of the commands, they are documented in the README.
+const (
+	ExitCodeError      = 1
+	ExitCodeChanges    = 2
+	ExitCodeValidation = 3
+	ExitCodeAPI        = 4
+	ExitCodeTimeout    = 5
+
+	// ExitCodeContainerCommand is what exec and run exit with when they fail
+	// themselves, any other code is the one of the command in the container
+	ExitCodeContainerCommand = 125
+)
+
+// Commands that exit with the exit code of a command run in a container.
+var containerCommands = map[string]bool{
+	"exec": true,
+	"run":  true,
+}
+
+const outputMetadataKey = "output"
+
+// validationError marks an error of the compose files or of the command line,
+// the API errors it wraps keep their own exit code.
+type validationError struct {
+	prefix string
+	err    error
+}
+
+func (e *validationError) Error() string {
+	return e.prefix + e.err.Error()
+}
+
+// containerExitError is the exit code of a command run in a container, it is
+// passed through as is by exec and run.
+type containerExitError struct {
+	code int
+}
+
+func (e *containerExitError) Error() string {
+	return fmt.Sprintf("The command exited with %d", e.code)
+}
+
+// exitCode classifies an error into one of the documented exit codes, errors
+// wrapped with github.com/pkg/errors are classified by their cause.
+func exitCode(err error) int {
+	cause := errors.Cause(err)
+	if cause == context.DeadlineExceeded {
+		return ExitCodeTimeout
+	}
+
+	switch cause := cause.(type) {
+	case nil:
+		return 0
+	case cli.ExitCoder:
+		return cause.ExitCode()
+	case *validationError:
+		if code := exitCode(cause.err); code != ExitCodeError {
+			return code
+		}
+		return ExitCodeValidation
+	case *client.ApiError:
+		return ExitCodeAPI
+	case net.Error:
+		if cause.Timeout() {
+			return ExitCodeTimeout
+		}
+		return ExitCodeAPI
+	}
+	return ExitCodeError
+}
+
+// commandExitCode is the exit code of a command. The exit code of a command
+// run in a container is passed through, so exec and run have a code of their
+// own for their failures that does not collide with it.
+func commandExitCode(command string, err error) int {
+	if containerExit, ok := errors.Cause(err).(*containerExitError); ok {
+		return containerExit.code
+	}
+
+	code := exitCode(err)
+	if code != 0 && containerCommands[command] {
+		return ExitCodeContainerCommand
+	}
+	return code
+}
+
+// withOutput turns the error of a command into its exit code and, in JSON
+// mode, ends the output with the summary of the command.
+func withOutput(action func(c *cli.Context) error) func(c *cli.Context) error {
+	return func(c *cli.Context) error {
+		err := action(c)
+		code := commandExitCode(c.Command.Name, err)
+
+		if output := outputFor(c); output != nil {
+			output.summary(c.Command.Name, err, code)
+			if err != nil {
+				return cli.NewExitError("", code)
+			}
+			return nil
+		}
+
+		if err == nil {
+			return nil
+		}
+		if _, ok := err.(*containerExitError); ok {
+			return cli.NewExitError("", code)
+		}
+		if _, ok := err.(cli.ExitCoder); ok {
+			return err
+		}
+		logrus.Error(err)
+		return cli.NewExitError("", code)
+	}
+}
+
+// outputFor returns the JSON output of the command, it is nil unless the
+// command was run with --output json.
+func outputFor(c *cli.Context) *jsonOutput {
+	if c.GlobalString("output") != "json" || c.App == nil {
+		return nil
+	}
+
+	if c.App.Metadata == nil {
+		c.App.Metadata = map[string]interface{}{}
+	}
+	output, ok := c.App.Metadata[outputMetadataKey].(*jsonOutput)
+	if !ok {
+		output = newJSONOutput(c.App.Writer)
+		c.App.Metadata[outputMetadataKey] = output
+	}
+	return output
+}
+
+// record is one line of the JSON output.
+type record struct {
+	Time      time.Time   `json:"time"`
+	Type      string      `json:"type"`
+	Event     string      `json:"event,omitempty"`
+	Message   string      `json:"message,omitempty"`
+	Service   string      `json:"service,omitempty"`
+	Container string      `json:"container,omitempty"`
+	Stream    string      `json:"stream,omitempty"`
+	Data      interface{} `json:"data,omitempty"`
+}
+
+type summaryRecord struct {
+	Time     time.Time        `json:"time"`
+	Type     string           `json:"type"`
+	Command  string           `json:"command"`
+	Success  bool             `json:"success"`
+	ExitCode int              `json:"exitCode"`
+	Error    string           `json:"error,omitempty"`
+	Services []serviceOutcome `json:"services"`
+}
+
+type serviceOutcome struct {
+	Service string `json:"service"`
+	Outcome string `json:"outcome"`
+	Event   string `json:"event"`
+	Error   string `json:"error,omitempty"`
+}
+
+// jsonOutput writes the output of a command as newline delimited JSON
+// records and keeps track of the outcome of every service from the project
+// events.
+type jsonOutput struct {
+	sync.Mutex
+	encoder  *json.Encoder
+	services map[string]*serviceOutcome
+	order    []string
+}
+
+func newJSONOutput(w io.Writer) *jsonOutput {
+	return &jsonOutput{
+		encoder:  json.NewEncoder(w),
+		services: map[string]*serviceOutcome{},
+	}
+}
+
+func (o *jsonOutput) write(v interface{}) {
+	o.Lock()
+	defer o.Unlock()
+	if err := o.encoder.Encode(v); err != nil {
+		logrus.Errorf("Failed to write output: %v", err)
+	}
+}
+
+// listen adds a listener to the project that writes every event, the
+// returned function must be called once the project stopped sending events.
+func (o *jsonOutput) listen(p *project.Project) func() {
+	listener := make(chan events.Event)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for event := range listener {
+			o.event(event)
+		}
+	}()
+
+	p.AddListener(listener)
+
+	return func() {
+		close(listener)
+		<-done
+	}
+}
+
+func (o *jsonOutput) event(event events.Event) {
+	var data interface{}
+	if len(event.Data) > 0 {
+		data = event.Data
+	}
+
+	o.write(&record{
+		Time:    time.Now().UTC(),
+		Type:    "event",
+		Event:   event.EventType.Name(),
+		Message: event.EventType.String(),
+		Service: event.ServiceName,
+		Data:    data,
+	})
+
+	if event.ServiceName == "" {
+		return
+	}
+
+	o.Lock()
+	defer o.Unlock()
+
+	outcome, ok := o.services[event.ServiceName]
+	if !ok {
+		outcome = &serviceOutcome{Service: event.ServiceName}
+		o.services[event.ServiceName] = outcome
+		o.order = append(o.order, event.ServiceName)
+	}
+
+	if outcome.Outcome == "failed" {
+		return
+	}
+
+	name := event.EventType.Name()
+	outcome.Event = name
+	switch {
+	case event.EventType == events.ServiceFailed:
+		outcome.Outcome = "failed"
+		outcome.Error = event.Data["error"]
+	case event.EventType == events.ServiceWaiting, strings.HasSuffix(name, "Start"):
+		outcome.Outcome = "incomplete"
+	default:
+		outcome.Outcome = "done"
+	}
+}
+
+// result writes what the command would print in text mode.
+func (o *jsonOutput) result(data interface{}) {
+	o.write(&record{
+		Time: time.Now().UTC(),
+		Type: "result",
+		Data: data,
+	})
+}
+
+func (o *jsonOutput) summary(command string, err error, code int) {
+	o.Lock()
+	services := []serviceOutcome{}
+	for _, name := range o.order {
+		services = append(services, *o.services[name])
+	}
+	o.Unlock()
+
+	summary := &summaryRecord{
+		Time:     time.Now().UTC(),
+		Type:     "summary",
+		Command:  command,
+		Success:  err == nil,
+		ExitCode: code,
+		Services: services,
+	}
+	if err != nil {
+		summary.Error = err.Error()
+	}
+
+	o.write(summary)
+}
+
+// Writer returns a writer that turns what is written to it into output
+// records of the given stream.
+func (o *jsonOutput) Writer(stream string) io.Writer {
+	return &outputWriter{
+		output: o,
+		stream: stream,
+	}
+}
+
+type outputWriter struct {
+	output *jsonOutput
+	stream string
+}
+
+func (w *outputWriter) Write(p []byte) (int, error) {
+	w.output.write(&record{
+		Time:    time.Now().UTC(),
+		Type:    "output",
+		Stream:  w.stream,
+		Message: string(p),
+	})
+	return len(p), nil
+}
+
+// CreateContainerLogger implements logger.Factory, the log lines of the
+// containers are written as records.
+func (o *jsonOutput) CreateContainerLogger(name string) logger.Logger {
+	return &jsonLogger{output: o, name: name}
+}
+
+func (o *jsonOutput) CreateBuildLogger(name string) logger.Logger {
+	return &jsonLogger{output: o, name: name}
+}
+
+func (o *jsonOutput) CreatePullLogger(name string) logger.Logger {
+	return &jsonLogger{output: o, name: name}
+}
+
+type jsonLogger struct {
+	output *jsonOutput
+	name   string
+}
+
+func (l *jsonLogger) log(stream string, bytes []byte) {
+	l.output.write(&record{
+		Time:      time.Now().UTC(),
+		Type:      "log",
+		Container: l.name,
+		Stream:    stream,
+		Message:   strings.TrimSuffix(string(bytes), "\n"),
+	})
+}
+
+func (l *jsonLogger) Out(bytes []byte) {
+	l.log("stdout", bytes)
+}
+
+func (l *jsonLogger) Err(bytes []byte) {
+	l.log("stderr", bytes)
+}
+
+func (l *jsonLogger) OutWriter() io.Writer {
+	return &logger.Wrapper{Logger: l}
+}
+
+func (l *jsonLogger) ErrWriter() io.Writer {
+	return &logger.Wrapper{Err: true, Logger: l}
+}
@@ -0,0 +1,85 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/pkg/errors"
+	"github.com/rancher/go-rancher/v2"
+	"github.com/rancher/rancher-compose-executor/project/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli"
+)
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestExitCode(t *testing.T) {
+	apiErr := &client.ApiError{StatusCode: 500, Msg: "Internal error"}
+	urlErr := &url.Error{Op: "Get", URL: "http://rancher", Err: errors.New("connection refused")}
+
+	assert.Equal(t, 0, exitCode(nil))
+	assert.Equal(t, ExitCodeError, exitCode(errors.New("failed")))
+	assert.Equal(t, ExitCodeChanges, exitCode(cli.NewExitError("", ExitCodeChanges)))
+	assert.Equal(t, ExitCodeValidation, exitCode(&validationError{err: errors.New("invalid")}))
+	assert.Equal(t, ExitCodeAPI, exitCode(apiErr))
+	assert.Equal(t, ExitCodeAPI, exitCode(urlErr))
+	assert.Equal(t, ExitCodeAPI, exitCode(&validationError{"Failed to read project: ", urlErr}))
+	assert.Equal(t, ExitCodeTimeout, exitCode(timeoutError{}))
+	assert.Equal(t, ExitCodeTimeout, exitCode(context.DeadlineExceeded))
+	assert.Equal(t, ExitCodeAPI, exitCode(errors.Wrap(apiErr, "Failed to set the scale web=2")))
+	assert.Equal(t, ExitCodeTimeout, exitCode(errors.Wrap(timeoutError{}, "Interrupted")))
+}
+
+func TestCommandExitCode(t *testing.T) {
+	apiErr := &client.ApiError{StatusCode: 500, Msg: "Internal error"}
+
+	assert.Equal(t, 0, commandExitCode("exec", nil))
+	assert.Equal(t, 4, commandExitCode("exec", &containerExitError{code: 4}))
+	assert.Equal(t, ExitCodeContainerCommand, commandExitCode("exec", apiErr))
+	assert.Equal(t, ExitCodeContainerCommand, commandExitCode("run", errors.New("failed")))
+	assert.Equal(t, ExitCodeAPI, commandExitCode("up", apiErr))
+}
+
+func TestJSONOutputSummary(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	output := newJSONOutput(buffer)
+
+	output.event(events.Event{EventType: events.ServiceUpStart, ServiceName: "web"})
+	output.event(events.Event{EventType: events.ServiceUp, ServiceName: "web"})
+	output.event(events.Event{EventType: events.ServiceUpStart, ServiceName: "db"})
+	output.event(events.Event{EventType: events.ServiceFailed, ServiceName: "db", Data: map[string]string{
+		"error": "boom",
+	}})
+	output.event(events.Event{EventType: events.ServiceUpStart, ServiceName: "cache"})
+	output.summary("up", errors.New("boom"), ExitCodeError)
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	assert.Len(t, lines, 6)
+
+	var event record
+	assert.NoError(t, json.Unmarshal([]byte(lines[3]), &event))
+	assert.Equal(t, "event", event.Type)
+	assert.Equal(t, "ServiceFailed", event.Event)
+	assert.Equal(t, "db", event.Service)
+	assert.Equal(t, map[string]interface{}{"error": "boom"}, event.Data)
+
+	var summary summaryRecord
+	assert.NoError(t, json.Unmarshal([]byte(lines[5]), &summary))
+	assert.Equal(t, "summary", summary.Type)
+	assert.False(t, summary.Success)
+	assert.Equal(t, ExitCodeError, summary.ExitCode)
+	assert.Equal(t, []serviceOutcome{
+		{Service: "web", Outcome: "done", Event: "ServiceUp"},
+		{Service: "db", Outcome: "failed", Event: "ServiceFailed", Error: "boom"},
+		{Service: "cache", Outcome: "incomplete", Event: "ServiceUpStart"},
+	}, summary.Services)
+}
@@ -0,0 +1,90 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Profile holds the settings to reach one Rancher server, the settings given
+// with flags or environment variables take precedence over it.
+type Profile struct {
+	URL                string `json:"url,omitempty"`
+	AccessKey          string `json:"accessKey,omitempty"`
+	SecretKey          string `json:"secretKey,omitempty"`
+	Environment        string `json:"environment,omitempty"`
+	CACert             string `json:"caCert,omitempty"`
+	InsecureSkipVerify bool   `json:"insecureSkipVerify,omitempty"`
+
+	name string
+}
+
+// profiles is the content of the profiles file, for example:
+//
+//	{
+//	  "defaultProfile": "staging",
+//	  "profiles": {
+//	    "staging": {"url": "https://staging.example.com", "accessKey": "...", "secretKey": "..."},
+//	    "prod": {"url": "https://rancher.example.com", "accessKey": "...", "secretKey": "...", "environment": "Default"}
+//	  }
+//	}
+type profiles struct {
+	DefaultProfile string             `json:"defaultProfile,omitempty"`
+	Profiles       map[string]Profile `json:"profiles"`
+}
+
+func profilesFile() string {
+	return filepath.Join(os.Getenv("HOME"), ".rancher", "compose.json")
+}
+
+// loadProfile reads a profile from the profiles file, the default profile of
+// the file is used when no name is given. A missing file or default profile
+// gives an empty profile, a missing named profile is an error.
+func loadProfile(file, name string) (*Profile, error) {
+	contents, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) && name == "" {
+		return &Profile{}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("Failed to read profile %s: %v", name, err)
+	}
+
+	var config profiles
+	if err := json.Unmarshal(contents, &config); err != nil {
+		return nil, fmt.Errorf("Failed to parse %s: %v", file, err)
+	}
+
+	if name == "" {
+		name = config.DefaultProfile
+		if name == "" {
+			return &Profile{}, nil
+		}
+	}
+
+	profile, ok := config.Profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("Profile %s is not defined in %s", name, file)
+	}
+	profile.name = name
+	return &profile, nil
+}
+
+// usesProfile tells if a profile applies to the server at the URL given with
+// a flag or an environment variable. The settings of a profile go together,
+// it only applies when no URL was given or it is the URL of the profile so
+// that its keys are never sent to another server.
+func usesProfile(url string, profile *Profile) bool {
+	return url == "" || strings.TrimSuffix(url, "/") == strings.TrimSuffix(profile.URL, "/")
+}
+
+// firstSet returns the first non empty value.
+func firstSet(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
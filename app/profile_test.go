@@ -0,0 +1,75 @@
+package app
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadProfile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "profiles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "compose.json")
+	profile, err := loadProfile(file, "")
+	assert.NoError(t, err)
+	assert.Equal(t, &Profile{}, profile)
+
+	_, err = loadProfile(file, "prod")
+	assert.Error(t, err)
+
+	err = ioutil.WriteFile(file, []byte(`{
+  "defaultProfile": "staging",
+  "profiles": {
+    "staging": {"url": "https://staging", "accessKey": "staging-key", "secretKey": "staging-secret"},
+    "prod": {"url": "https://prod", "environment": "Default", "insecureSkipVerify": true}
+  }
+}`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	profile, err = loadProfile(file, "")
+	assert.NoError(t, err)
+	assert.Equal(t, &Profile{
+		URL:       "https://staging",
+		AccessKey: "staging-key",
+		SecretKey: "staging-secret",
+		name:      "staging",
+	}, profile)
+
+	profile, err = loadProfile(file, "prod")
+	assert.NoError(t, err)
+	assert.Equal(t, &Profile{
+		URL:                "https://prod",
+		Environment:        "Default",
+		InsecureSkipVerify: true,
+		name:               "prod",
+	}, profile)
+
+	_, err = loadProfile(file, "dev")
+	assert.EqualError(t, err, "Profile dev is not defined in "+file)
+}
+
+func TestFirstSet(t *testing.T) {
+	assert.Equal(t, "flag", firstSet("flag", "profile"))
+	assert.Equal(t, "profile", firstSet("", "profile"))
+	assert.Equal(t, "", firstSet("", ""))
+}
+
+func TestUsesProfile(t *testing.T) {
+	profile := &Profile{URL: "https://rancher.example.com/", AccessKey: "key", SecretKey: "secret"}
+
+	assert.True(t, usesProfile("", profile))
+	assert.True(t, usesProfile("https://rancher.example.com", profile))
+	// A URL from the environment for another server must not get the keys of
+	// the profile
+	assert.False(t, usesProfile("https://staging.example.com", profile))
+	assert.True(t, usesProfile("", &Profile{}))
+}
@@ -0,0 +1,267 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/pkg/term"
+	"github.com/rancher/rancher-compose-executor/project"
+	"github.com/rancher/rancher-compose-executor/project/events"
+	"github.com/urfave/cli"
+)
+
+const progressInterval = 250 * time.Millisecond
+
+// progressPhases maps the events of a service to the phase shown in its row,
+// the phases that are not running stop the elapsed time of the row.
+var progressPhases = map[events.EventType]struct {
+	phase   string
+	running bool
+}{
+	events.ServiceWaiting:          {"waiting on dependencies", true},
+	events.ServiceCreateStart:      {"creating", true},
+	events.ServiceCreate:           {"created", false},
+	events.ServiceUpStart:          {"starting", true},
+	events.ServiceUpIgnored:        {"ignored", false},
+	events.ServiceUp:               {"up", false},
+	events.ServiceUpgradeStart:     {"upgrading", true},
+	events.ServiceUpgrade:          {"upgraded", true},
+	events.ServiceHealthCheckStart: {"waiting for health", true},
+	events.ServiceHealthCheck:      {"healthy", true},
+	events.ServicePullStart:        {"pulling", true},
+	events.ServicePull:             {"pulled", true},
+	events.ServiceFailed:           {"failed", false},
+}
+
+// withProgress runs the action with a live view of the services on the
+// terminal, the plain log lines of the default listener are kept when stdout
+// is not a terminal, in JSON mode and in verbose mode.
+func withProgress(p *project.Project, c *cli.Context, action func() error) error {
+	fd, isTerminal := term.GetFdInfo(os.Stdout)
+	if !isTerminal || outputFor(c) != nil || c.GlobalBool("verbose") {
+		return action()
+	}
+
+	view := newProgressView(os.Stdout, func() (int, int) {
+		size, err := term.GetWinsize(fd)
+		if err != nil {
+			return 0, 0
+		}
+		return int(size.Width), int(size.Height)
+	})
+	p.AddListener(view.listener)
+
+	// Log lines are printed above the rows, only the warnings and errors are
+	// kept since the rows already show the progress.
+	logger := logrus.StandardLogger()
+	out, level := logger.Out, logger.Level
+	logrus.SetOutput(view)
+	if level > logrus.WarnLevel {
+		logrus.SetLevel(logrus.WarnLevel)
+	}
+	defer func() {
+		view.stop()
+		logrus.SetOutput(out)
+		logrus.SetLevel(level)
+	}()
+
+	return action()
+}
+
+type progressRow struct {
+	name     string
+	phase    string
+	detail   string
+	started  time.Time
+	finished time.Time
+}
+
+func (r *progressRow) elapsed(now time.Time) time.Duration {
+	if !r.finished.IsZero() {
+		now = r.finished
+	}
+	return (now.Sub(r.started) / time.Second) * time.Second
+}
+
+// progressView renders one row per service with its phase and the time
+// since its first event, the rows are drawn again in place as events come
+// in and while waiting.
+type progressView struct {
+	sync.Mutex
+	out      io.Writer
+	size     func() (int, int)
+	listener chan events.Event
+	stopped  chan struct{}
+	done     chan struct{}
+	rows     map[string]*progressRow
+	order    []string
+	drawn    int
+}
+
+func newProgressView(out io.Writer, size func() (int, int)) *progressView {
+	v := &progressView{
+		out:      out,
+		size:     size,
+		listener: make(chan events.Event),
+		stopped:  make(chan struct{}),
+		done:     make(chan struct{}),
+		rows:     map[string]*progressRow{},
+	}
+	go v.run()
+	return v
+}
+
+// run keeps draining the listener after the view is stopped, the project
+// still sends events to it once the action is over.
+func (v *progressView) run() {
+	ticker := time.NewTicker(progressInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case event := <-v.listener:
+			v.Lock()
+			v.update(event, time.Now())
+			v.draw(time.Now())
+			v.Unlock()
+		case <-ticker.C:
+			v.Lock()
+			v.draw(time.Now())
+			v.Unlock()
+		case <-v.stopped:
+			v.Lock()
+			v.draw(time.Now())
+			v.drawn = 0
+			close(v.done)
+			v.Unlock()
+			for range v.listener {
+			}
+			return
+		}
+	}
+}
+
+func (v *progressView) stop() {
+	close(v.stopped)
+	<-v.done
+}
+
+func (v *progressView) update(event events.Event, now time.Time) {
+	phase, ok := progressPhases[event.EventType]
+	if !ok || event.ServiceName == "" {
+		return
+	}
+
+	row, ok := v.rows[event.ServiceName]
+	if !ok {
+		row = &progressRow{
+			name:    event.ServiceName,
+			started: now,
+		}
+		v.rows[event.ServiceName] = row
+		v.order = append(v.order, event.ServiceName)
+	}
+
+	if row.phase == "failed" {
+		return
+	}
+
+	row.phase = phase.phase
+	row.detail = event.Data["error"]
+	if phase.running {
+		row.finished = time.Time{}
+	} else {
+		row.finished = now
+	}
+}
+
+// Write prints log lines above the rows.
+func (v *progressView) Write(p []byte) (int, error) {
+	v.Lock()
+	defer v.Unlock()
+
+	v.clear()
+	n, err := v.out.Write(p)
+	v.draw(time.Now())
+	return n, err
+}
+
+func (v *progressView) clear() {
+	if v.drawn > 0 {
+		fmt.Fprintf(v.out, "\x1b[%dA\x1b[J", v.drawn)
+		v.drawn = 0
+	}
+}
+
+func (v *progressView) draw(now time.Time) {
+	select {
+	case <-v.done:
+		return
+	default:
+	}
+
+	width, height := v.size()
+	lines := v.render(now, width, height)
+
+	buffer := &bytes.Buffer{}
+	if v.drawn > 0 {
+		fmt.Fprintf(buffer, "\x1b[%dA\x1b[J", v.drawn)
+	}
+	for _, line := range lines {
+		buffer.WriteString(line)
+		buffer.WriteString("\n")
+	}
+	v.out.Write(buffer.Bytes())
+	v.drawn = len(lines)
+}
+
+// render returns the rows to draw, they are cut to the width of the terminal
+// so that they never wrap. When there are more rows than the terminal can
+// show, the running ones come first and the finished ones are counted.
+func (v *progressView) render(now time.Time, width, height int) []string {
+	rows := []*progressRow{}
+	nameWidth := 0
+	for _, name := range v.order {
+		rows = append(rows, v.rows[name])
+		if len(name) > nameWidth {
+			nameWidth = len(name)
+		}
+	}
+
+	hidden := 0
+	if height > 1 && len(rows) > height-1 {
+		sort.SliceStable(rows, func(i, j int) bool {
+			return rows[i].finished.IsZero() && !rows[j].finished.IsZero()
+		})
+		hidden = len(rows) - (height - 2)
+		rows = rows[:height-2]
+	}
+
+	lines := []string{}
+	for _, row := range rows {
+		line := fmt.Sprintf("%-*s  %-24s %6s", nameWidth, row.name, row.phase, row.elapsed(now))
+		if row.detail != "" {
+			line += "  " + row.detail
+		}
+		lines = append(lines, line)
+	}
+	if hidden > 0 {
+		lines = append(lines, fmt.Sprintf("... %d more services", hidden))
+	}
+
+	if width > 1 {
+		for i, line := range lines {
+			if len(line) > width-1 {
+				lines[i] = line[:width-1]
+			}
+		}
+	}
+
+	return lines
+}
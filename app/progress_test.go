@@ -0,0 +1,50 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rancher/rancher-compose-executor/project/events"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProgressViewRender(t *testing.T) {
+	v := &progressView{rows: map[string]*progressRow{}}
+	start := time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	v.update(events.Event{EventType: events.ServiceCreateStart, ServiceName: "db"}, start)
+	v.update(events.Event{EventType: events.ServiceWaiting, ServiceName: "web"}, start)
+	v.update(events.Event{EventType: events.ServiceCreate, ServiceName: "db"}, start.Add(3*time.Second))
+	v.update(events.Event{EventType: events.ServiceCreateStart, ServiceName: "web"}, start.Add(3*time.Second))
+	v.update(events.Event{EventType: events.ServiceFailed, ServiceName: "web", Data: map[string]string{
+		"error": "Bad image",
+	}}, start.Add(4*time.Second))
+	v.update(events.Event{EventType: events.ProjectCreateDone}, start.Add(4*time.Second))
+
+	now := start.Add(10 * time.Second)
+	assert.Equal(t, []string{
+		"db   created                      3s",
+		"web  failed                       4s  Bad image",
+	}, v.render(now, 80, 24))
+
+	assert.Equal(t, []string{
+		"db   created      ",
+		"web  failed       ",
+	}, v.render(now, 19, 24))
+}
+
+func TestProgressViewRenderHeight(t *testing.T) {
+	v := &progressView{rows: map[string]*progressRow{}}
+	start := time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, name := range []string{"a", "b", "c", "d"} {
+		v.update(events.Event{EventType: events.ServiceUpStart, ServiceName: name}, start)
+	}
+	v.update(events.Event{EventType: events.ServiceUp, ServiceName: "a"}, start)
+
+	assert.Equal(t, []string{
+		"b  starting                     1s",
+		"c  starting                     1s",
+		"... 2 more services",
+	}, v.render(start.Add(time.Second), 80, 4))
+}
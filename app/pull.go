@@ -0,0 +1,85 @@
+package app
+
+import (
+	"sort"
+
+	"github.com/rancher/rancher-compose-executor/project"
+	"github.com/rancher/rancher-compose-executor/project/events"
+)
+
+// pullResults collects the per host status of every pulled image from the
+// project events.
+type pullResults struct {
+	listener chan events.Event
+	done     chan struct{}
+	images   map[string]map[string]string
+	hosts    map[string]bool
+}
+
+func newPullResults() *pullResults {
+	r := &pullResults{
+		listener: make(chan events.Event),
+		done:     make(chan struct{}),
+		images:   map[string]map[string]string{},
+		hosts:    map[string]bool{},
+	}
+	go r.collect()
+	return r
+}
+
+func (r *pullResults) collect() {
+	defer close(r.done)
+	for event := range r.listener {
+		if event.EventType != events.ServicePull || event.Data["image"] == "" {
+			continue
+		}
+
+		image, host := event.Data["image"], event.Data["host"]
+		if r.images[image] == nil {
+			r.images[image] = map[string]string{}
+		}
+		r.images[image][host] = event.Data["status"]
+		r.hosts[host] = true
+	}
+}
+
+// close must only be called once the project stopped sending events, it waits
+// for every received event to be collected.
+func (r *pullResults) close() {
+	close(r.listener)
+	<-r.done
+}
+
+// String renders the results as a matrix with one row per image and one
+// column per host.
+func (r *pullResults) String() string {
+	if len(r.images) == 0 {
+		return ""
+	}
+
+	hosts := []string{}
+	for host := range r.hosts {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+
+	images := []string{}
+	for image := range r.images {
+		images = append(images, image)
+	}
+	sort.Strings(images)
+
+	infos := project.InfoSet{}
+	for _, image := range images {
+		info := project.Info{"Image": image}
+		for _, host := range hosts {
+			info[host] = r.images[image][host]
+			if info[host] == "" {
+				info[host] = "-"
+			}
+		}
+		infos = append(infos, info)
+	}
+
+	return infos.String(append([]string{"Image"}, hosts...), true)
+}
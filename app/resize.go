@@ -0,0 +1,50 @@
+//go:build !windows
+// +build !windows
+
+package app
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/docker/docker/pkg/term"
+	"github.com/rancher/rancher-compose-executor/project/options"
+)
+
+// notifyResize sends the size of the terminal every time it changes, a size
+// not yet received is replaced by the newer one.
+func notifyResize(fd uintptr) (<-chan options.TerminalSize, func()) {
+	signals := make(chan os.Signal, 1)
+	sizes := make(chan options.TerminalSize, 1)
+	done := make(chan struct{})
+	signal.Notify(signals, syscall.SIGWINCH)
+
+	go func() {
+		for {
+			select {
+			case <-signals:
+			case <-done:
+				return
+			}
+
+			size, err := term.GetWinsize(fd)
+			if err != nil {
+				continue
+			}
+			select {
+			case <-sizes:
+			default:
+			}
+			sizes <- options.TerminalSize{
+				Width:  int(size.Width),
+				Height: int(size.Height),
+			}
+		}
+	}()
+
+	return sizes, func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
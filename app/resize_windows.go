@@ -0,0 +1,10 @@
+package app
+
+import (
+	"github.com/rancher/rancher-compose-executor/project/options"
+)
+
+// notifyResize does not report changes on Windows, which has no SIGWINCH.
+func notifyResize(fd uintptr) (<-chan options.TerminalSize, func()) {
+	return nil, func() {}
+}
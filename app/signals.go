@@ -0,0 +1,73 @@
+package app
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+// interrupter cancels the context of a command on the first SIGINT or
+// SIGTERM, a second signal is reported separately so that the command can
+// escalate.
+type interrupter struct {
+	signals chan os.Signal
+	second  chan struct{}
+	done    chan struct{}
+	cancel  context.CancelFunc
+	once    sync.Once
+}
+
+func newInterrupter() (context.Context, *interrupter) {
+	ctx, cancel := context.WithCancel(context.Background())
+	i := &interrupter{
+		signals: make(chan os.Signal, 2),
+		second:  make(chan struct{}),
+		done:    make(chan struct{}),
+		cancel:  cancel,
+	}
+	signal.Notify(i.signals, syscall.SIGINT, syscall.SIGTERM)
+	go i.run()
+	return ctx, i
+}
+
+func (i *interrupter) run() {
+	count := 0
+	for {
+		select {
+		case <-i.signals:
+			count++
+			switch count {
+			case 1:
+				i.cancel()
+			case 2:
+				close(i.second)
+			}
+		case <-i.done:
+			return
+		}
+	}
+}
+
+// again reports whether a second signal is received within the timeout.
+func (i *interrupter) again(timeout time.Duration) bool {
+	select {
+	case <-i.second:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// stop restores the default handling of the signals, a signal received
+// afterwards ends the process right away.
+func (i *interrupter) stop() {
+	i.once.Do(func() {
+		signal.Stop(i.signals)
+		close(i.done)
+		i.cancel()
+	})
+}
@@ -0,0 +1,27 @@
+package app
+
+import (
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterrupter(t *testing.T) {
+	ctx, interrupts := newInterrupter()
+	defer interrupts.stop()
+
+	syscall.Kill(os.Getpid(), syscall.SIGINT)
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("The context was not cancelled by the first signal")
+	}
+	assert.False(t, interrupts.again(10*time.Millisecond))
+
+	syscall.Kill(os.Getpid(), syscall.SIGTERM)
+	assert.True(t, interrupts.again(5*time.Second))
+}
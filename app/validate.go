@@ -0,0 +1,79 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/rancher/rancher-compose-executor/config"
+	"github.com/rancher/rancher-compose-executor/project"
+	"github.com/rancher/rancher-compose-executor/rancher"
+	"github.com/rancher/rancher-compose-executor/template"
+	"github.com/urfave/cli"
+)
+
+func ValidateCommand() cli.Command {
+	return cli.Command{
+		Name:   "validate",
+		Usage:  "Check the compose files without connecting to Rancher",
+		Action: withOutput(validateProject),
+	}
+}
+
+// validateProject reports every problem of the compose files. The files are
+// checked against the schema one by one, then the references between services
+// are checked on the merged project. That is skipped only when the files can
+// not be merged, which the schema problems then explain.
+func validateProject(c *cli.Context) error {
+	context, err := newContext(c)
+	if err != nil {
+		return &validationError{err: err}
+	}
+
+	if err := project.NewProject(&context.Context).Open(); err != nil {
+		return &validationError{err: err}
+	}
+
+	problems := []string{}
+	for i, contents := range context.ComposeBytes {
+		file := context.ComposeFiles[i]
+		err := config.Validate(context.EnvironmentLookup, template.ReleaseInfo{
+			Version:         context.Version,
+			PreviousVersion: context.PreviousVersion,
+		}, contents)
+		if err != nil {
+			for _, line := range strings.Split(err.Error(), "\n") {
+				problems = append(problems, fmt.Sprintf("%s: %s", file, line))
+			}
+		}
+	}
+
+	p, err := rancher.NewOfflineProject(context)
+	if err != nil {
+		if len(problems) == 0 {
+			problems = append(problems, err.Error())
+		}
+	} else {
+		referenceProblems, err := rancher.Validate(p)
+		if err != nil {
+			return err
+		}
+		problems = append(problems, referenceProblems...)
+	}
+
+	if output := outputFor(c); output != nil {
+		output.result(problems)
+	} else {
+		for _, problem := range problems {
+			fmt.Fprintln(os.Stderr, problem)
+		}
+	}
+
+	if len(problems) > 0 {
+		return &validationError{err: fmt.Errorf("Found %d problem(s)", len(problems))}
+	}
+
+	logrus.Infof("Project %s is valid", context.ProjectName)
+	return nil
+}
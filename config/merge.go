@@ -107,56 +107,7 @@ func CreateRawConfig(contents []byte) (*RawConfig, error) {
 // TODO: get rid of existingServices
 // Merge merges a compose file into an existing set of service configs
 func Merge(existingServices *ServiceConfigs, environmentLookup EnvironmentLookup, resourceLookup ResourceLookup, releaseInfo template.ReleaseInfo, file string, contents []byte) (*Config, error) {
-	var err error
-	contents, err = template.Apply(contents, releaseInfo, environmentLookup.Variables())
-	if err != nil {
-		return nil, err
-	}
-
-	rawConfig, err := CreateRawConfig(contents)
-	if err != nil {
-		return nil, err
-	}
-
-	baseRawServices := rawConfig.Services
-	baseRawContainers := rawConfig.Containers
-
-	// TODO: just interpolate at the map level earlier
-	if err := InterpolateRawServiceMap(&baseRawServices, environmentLookup); err != nil {
-		return nil, err
-	}
-	if err := InterpolateRawServiceMap(&baseRawContainers, environmentLookup); err != nil {
-		return nil, err
-	}
-
-	for k, v := range rawConfig.Volumes {
-		if err := Interpolate(k, &v, environmentLookup); err != nil {
-			return nil, err
-		}
-		rawConfig.Volumes[k] = v
-	}
-
-	for k, v := range rawConfig.Networks {
-		if err := Interpolate(k, &v, environmentLookup); err != nil {
-			return nil, err
-		}
-		rawConfig.Networks[k] = v
-	}
-
-	baseRawServices, err = PreprocessServiceMap(baseRawServices)
-	if err != nil {
-		return nil, err
-	}
-	baseRawContainers, err = PreprocessServiceMap(baseRawContainers)
-	if err != nil {
-		return nil, err
-	}
-
-	baseRawServices, err = TryConvertStringsToInts(baseRawServices, getRancherConfigObjects())
-	if err != nil {
-		return nil, err
-	}
-	baseRawContainers, err = TryConvertStringsToInts(baseRawContainers, getRancherConfigObjects())
+	rawConfig, baseRawServices, baseRawContainers, err := loadRawConfig(environmentLookup, releaseInfo, contents)
 	if err != nil {
 		return nil, err
 	}
@@ -247,6 +198,66 @@ func Merge(existingServices *ServiceConfigs, environmentLookup EnvironmentLookup
 	}, nil
 }
 
+// loadRawConfig renders the template of a compose file and returns its
+// interpolated and preprocessed services and containers, ready to be
+// validated and merged.
+func loadRawConfig(environmentLookup EnvironmentLookup, releaseInfo template.ReleaseInfo, contents []byte) (*RawConfig, RawServiceMap, RawServiceMap, error) {
+	contents, err := template.Apply(contents, releaseInfo, environmentLookup.Variables())
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	rawConfig, err := CreateRawConfig(contents)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	baseRawServices := rawConfig.Services
+	baseRawContainers := rawConfig.Containers
+
+	// TODO: just interpolate at the map level earlier
+	if err := InterpolateRawServiceMap(&baseRawServices, environmentLookup); err != nil {
+		return nil, nil, nil, err
+	}
+	if err := InterpolateRawServiceMap(&baseRawContainers, environmentLookup); err != nil {
+		return nil, nil, nil, err
+	}
+
+	for k, v := range rawConfig.Volumes {
+		if err := Interpolate(k, &v, environmentLookup); err != nil {
+			return nil, nil, nil, err
+		}
+		rawConfig.Volumes[k] = v
+	}
+
+	for k, v := range rawConfig.Networks {
+		if err := Interpolate(k, &v, environmentLookup); err != nil {
+			return nil, nil, nil, err
+		}
+		rawConfig.Networks[k] = v
+	}
+
+	baseRawServices, err = PreprocessServiceMap(baseRawServices)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	baseRawContainers, err = PreprocessServiceMap(baseRawContainers)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	baseRawServices, err = TryConvertStringsToInts(baseRawServices, getRancherConfigObjects())
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	baseRawContainers, err = TryConvertStringsToInts(baseRawContainers, getRancherConfigObjects())
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return rawConfig, baseRawServices, baseRawContainers, nil
+}
+
 func InterpolateRawServiceMap(baseRawServices *RawServiceMap, environmentLookup EnvironmentLookup) error {
 	for k, v := range *baseRawServices {
 		for k2, v2 := range v {
@@ -88,7 +88,7 @@ type ServiceConfigV1 struct {
 	ExtraHosts        []string             `yaml:"extra_hosts,omitempty"`
 	Ulimits           yaml.Ulimits         `yaml:"ulimits,omitempty"`
 
-	LbConfig                 *LBConfig                        `yaml:"lb_config"`
+	LbConfig                 *LBConfig                        `yaml:"lb_config,omitempty"`
 	LegacyLoadBalancerConfig *legacyClient.LoadBalancerConfig `yaml:"load_balancer_config,omitempty"`
 	DefaultCert              string                           `yaml:"default_cert,omitempty"`
 	Certs                    []string                         `yaml:"certs,omitempty"`
@@ -193,7 +193,7 @@ type ServiceConfig struct {
 }
 
 type RancherConfig struct {
-	LbConfig                 *LBConfig                        `yaml:"lb_config"`
+	LbConfig                 *LBConfig                        `yaml:"lb_config,omitempty"`
 	LegacyLoadBalancerConfig *legacyClient.LoadBalancerConfig `yaml:"load_balancer_config,omitempty"`
 	DefaultCert              string                           `yaml:"default_cert,omitempty"`
 	Certs                    []string                         `yaml:"certs,omitempty"`
@@ -1,10 +1,12 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
 
+	"github.com/rancher/rancher-compose-executor/template"
 	"github.com/xeipuuv/gojsonschema"
 )
 
@@ -188,6 +190,32 @@ func invalidTypeMessage(service, key string, err gojsonschema.ResultError) strin
 	return fmt.Sprintf("Service '%s' configuration key '%s' contains an invalid type, it should be %s.", service, key, validTypesMsg)
 }
 
+// Validate runs the schema checks on the services and containers of a compose
+// file without merging it, so every problem of the file is reported at once.
+func Validate(environmentLookup EnvironmentLookup, releaseInfo template.ReleaseInfo, contents []byte) error {
+	rawConfig, baseRawServices, baseRawContainers, err := loadRawConfig(environmentLookup, releaseInfo, contents)
+	if err != nil {
+		return err
+	}
+
+	if rawConfig.Version != "2" {
+		return validate(baseRawServices)
+	}
+
+	var validationErrors []string
+	for _, serviceMap := range []RawServiceMap{baseRawServices, baseRawContainers} {
+		if err := validateV2(serviceMap); err != nil {
+			validationErrors = append(validationErrors, err.Error())
+		}
+	}
+
+	if len(validationErrors) > 0 {
+		return errors.New(strings.Join(validationErrors, "\n"))
+	}
+
+	return nil
+}
+
 func validate(serviceMap RawServiceMap) error {
 	if err := setupSchemaLoaders(schemaDataV1, &schemaV1, &schemaLoaderV1, &constraintSchemaLoaderV1); err != nil {
 		return err
@@ -275,7 +303,7 @@ func generateErrorMessages(serviceMap RawServiceMap, schema map[string]interface
 			}
 		}
 
-		return fmt.Errorf(strings.Join(validationErrors, "\n"))
+		return errors.New(strings.Join(validationErrors, "\n"))
 	}
 
 	return nil
@@ -314,7 +342,7 @@ func validateServiceConstraints(service RawService, serviceName string) error {
 			}
 		}
 
-		return fmt.Errorf(strings.Join(validationErrors, "\n"))
+		return errors.New(strings.Join(validationErrors, "\n"))
 	}
 
 	return nil
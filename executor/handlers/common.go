@@ -2,9 +2,7 @@ package handlers
 
 import (
 	"errors"
-	"fmt"
 
-	"github.com/Sirupsen/logrus"
 	"github.com/rancher/event-subscriber/events"
 	"github.com/rancher/go-rancher/v2"
 )
@@ -48,14 +46,3 @@ func reply(event *events.Event, apiClient *client.RancherClient, data map[string
 	reply.Data = data
 	return publishReply(reply, apiClient)
 }
-
-func WithTimeout(f func(event *events.Event, apiClient *client.RancherClient) error) func(event *events.Event, apiClient *client.RancherClient) error {
-	return func(event *events.Event, apiClient *client.RancherClient) error {
-		err := f(event, apiClient)
-		if err == ErrTimeout {
-			logrus.Infof("Timeout processing %s", fmt.Sprintf("%s:%s", event.ResourceType, event.ResourceID))
-			return nil
-		}
-		return nil
-	}
-}
@@ -20,8 +20,6 @@ func CreateStack(event *events.Event, apiClient *client.RancherClient) error {
 	logger.Info("Stack Create Event Received")
 
 	if err := createStack(logger, event, apiClient); err != nil {
-		logger.Errorf("Stack Create Event Failed: %v", err)
-		publishTransitioningReply(err.Error(), event, apiClient, true)
 		return err
 	}
 
@@ -43,12 +41,18 @@ func createStack(logger *logrus.Entry, event *events.Event, apiClient *client.Ra
 		return emptyReply(event, apiClient)
 	}
 
+	if err := validateAnswers(stack.RancherCompose, stack.Environment, true); err != nil {
+		return err
+	}
+
 	_, project, err := constructProject(logger, stack, apiClient.GetOpts().Url, apiClient.GetOpts().AccessKey, apiClient.GetOpts().SecretKey)
 	if err != nil {
 		return err
 	}
 
 	publishTransitioningReply("Creating stack", event, apiClient, false)
+	reporter := newProgressReporter(event, apiClient, project)
+	defer reporter.stop()
 
 	if err := project.Create(context.Background(), options.Create{}); err != nil {
 		return err
@@ -76,5 +80,6 @@ func createStack(logger *logrus.Entry, event *events.Event, apiClient *client.Ra
 		}
 	}
 
+	reporter.stop()
 	return emptyReply(event, apiClient)
 }
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"golang.org/x/net/context"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/rancher/event-subscriber/events"
+	"github.com/rancher/go-rancher/v2"
+	"github.com/rancher/rancher-compose-executor/project"
+	"github.com/rancher/rancher-compose-executor/project/options"
+)
+
+// RemoveStack removes the services in reverse dependency order and then the
+// volumes of the stack, external volumes are left alone. Secrets are shared
+// by the environment so they are never removed with a stack.
+func RemoveStack(event *events.Event, apiClient *client.RancherClient) error {
+	return lifecycle(event, apiClient, "Remove", "Removing stack", func(p *project.Project) error {
+		return p.Down(context.Background(), options.Down{
+			RemoveVolume: true,
+		})
+	})
+}
+
+// ActivateStack starts the services in dependency order.
+func ActivateStack(event *events.Event, apiClient *client.RancherClient) error {
+	return lifecycle(event, apiClient, "Activate", "Activating stack", func(p *project.Project) error {
+		return p.Start(context.Background())
+	})
+}
+
+// DeactivateStack stops the services in reverse dependency order.
+func DeactivateStack(event *events.Event, apiClient *client.RancherClient) error {
+	return lifecycle(event, apiClient, "Deactivate", "Deactivating stack", func(p *project.Project) error {
+		return p.Stop(context.Background())
+	})
+}
+
+func lifecycle(event *events.Event, apiClient *client.RancherClient, name, message string, action func(p *project.Project) error) error {
+	logger := logrus.WithFields(logrus.Fields{
+		"resourceId": event.ResourceID,
+		"eventId":    event.ID,
+	})
+
+	logger.Infof("%s Stack Event Received", name)
+
+	stack, err := apiClient.Stack.ById(event.ResourceID)
+	if err != nil {
+		return err
+	}
+
+	// Nothing is left to do for stacks that are already gone or that were
+	// not created from a compose file
+	if stack == nil || stack.DockerCompose == "" {
+		return emptyReply(event, apiClient)
+	}
+
+	_, project, err := constructProject(logger, stack, apiClient.GetOpts().Url, apiClient.GetOpts().AccessKey, apiClient.GetOpts().SecretKey)
+	if err != nil {
+		return err
+	}
+
+	publishTransitioningReply(message, event, apiClient, false)
+	reporter := newProgressReporter(event, apiClient, project)
+	defer reporter.stop()
+
+	if err := action(project); err != nil {
+		return err
+	}
+
+	reporter.stop()
+	logger.Infof("%s Stack Event Done", name)
+	return emptyReply(event, apiClient)
+}
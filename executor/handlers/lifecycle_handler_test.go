@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/rancher/event-subscriber/events"
+	"github.com/rancher/go-rancher/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeStacks struct {
+	client.StackOperations
+	stacks map[string]*client.Stack
+}
+
+func (f *fakeStacks) ById(id string) (*client.Stack, error) {
+	return f.stacks[id], nil
+}
+
+func TestLifecycleWithoutCompose(t *testing.T) {
+	publish := &fakePublish{}
+	apiClient := &client.RancherClient{
+		Publish: publish,
+		Stack: &fakeStacks{stacks: map[string]*client.Stack{
+			"1st1": {Resource: client.Resource{Id: "1st1"}, Name: "web"},
+		}},
+	}
+
+	for _, id := range []string{"1st1", "1st2"} {
+		publish.replies = nil
+		event := &events.Event{ID: "event-" + id, ReplyTo: "reply", ResourceID: id}
+
+		assert.NoError(t, RemoveStack(event, apiClient))
+		if assert.Len(t, publish.replies, 1) {
+			assert.Equal(t, "", publish.replies[0].Transitioning)
+			assert.Equal(t, []string{"event-" + id}, publish.replies[0].PreviousIds)
+		}
+	}
+}
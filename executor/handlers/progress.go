@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rancher/event-subscriber/events"
+	"github.com/rancher/go-rancher/v2"
+	"github.com/rancher/rancher-compose-executor/project"
+	projectEvents "github.com/rancher/rancher-compose-executor/project/events"
+)
+
+// progressInterval is the least time between two transitioning replies, the
+// UI does not need more and every reply is a request to the API.
+const progressInterval = 2 * time.Second
+
+// progressVerbs maps the events that start working on a service to the verb
+// shown for it, progressDone the events that are done with it.
+var (
+	progressVerbs = map[projectEvents.EventType]string{
+		projectEvents.ServiceCreateStart:  "Creating",
+		projectEvents.ServiceUpStart:      "Starting",
+		projectEvents.ServiceUpgradeStart: "Upgrading",
+		projectEvents.ServiceStartStart:   "Starting",
+		projectEvents.ServiceStopStart:    "Stopping",
+		projectEvents.ServiceDownStart:    "Removing",
+		projectEvents.ServiceDeleteStart:  "Removing",
+		projectEvents.ServicePullStart:    "Pulling",
+	}
+	progressDone = map[projectEvents.EventType]bool{
+		projectEvents.ServiceCreate:    true,
+		projectEvents.ServiceUp:        true,
+		projectEvents.ServiceUpIgnored: true,
+		projectEvents.ServiceStart:     true,
+		projectEvents.ServiceStop:      true,
+		projectEvents.ServiceDown:      true,
+		projectEvents.ServiceDelete:    true,
+		projectEvents.ServicePull:      true,
+		projectEvents.ServiceFailed:    true,
+	}
+)
+
+type serviceProgress struct {
+	verb    string
+	health  bool
+	done    bool
+	failed  bool
+	started int
+}
+
+// progressReporter turns the events of a project into transitioning replies
+// like "Upgrading web (3/12), waiting on db health", so that the UI shows
+// where a long create or upgrade is.
+type progressReporter struct {
+	event     *events.Event
+	apiClient *client.RancherClient
+	project   *project.Project
+	total     int
+	listener  chan projectEvents.Event
+	stopped   chan struct{}
+	done      chan struct{}
+	once      sync.Once
+	services  map[string]*serviceProgress
+	counter   int
+	published string
+	last      time.Time
+}
+
+func newProgressReporter(event *events.Event, apiClient *client.RancherClient, p *project.Project) *progressReporter {
+	r := &progressReporter{
+		event:     event,
+		apiClient: apiClient,
+		project:   p,
+		total:     p.ServiceConfigs.Len(),
+		listener:  make(chan projectEvents.Event),
+		stopped:   make(chan struct{}),
+		done:      make(chan struct{}),
+		services:  map[string]*serviceProgress{},
+	}
+	p.AddListener(r.listener)
+	go r.run()
+	return r
+}
+
+func (r *progressReporter) run() {
+	ticker := time.NewTicker(progressInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case event := <-r.listener:
+			r.update(event)
+			r.publish(time.Now())
+		case <-ticker.C:
+			r.publish(time.Now())
+		case <-r.stopped:
+			close(r.done)
+			return
+		}
+	}
+}
+
+// stop ends the replies, it is called before the final reply to the event so
+// that no progress comes after it. The listener is removed first, the project
+// must not block on it once run has returned.
+func (r *progressReporter) stop() {
+	r.once.Do(func() {
+		r.project.RemoveListener(r.listener)
+		close(r.stopped)
+		<-r.done
+	})
+}
+
+func (r *progressReporter) update(event projectEvents.Event) {
+	if event.ServiceName == "" {
+		return
+	}
+
+	service, ok := r.services[event.ServiceName]
+	if !ok {
+		service = &serviceProgress{}
+		r.services[event.ServiceName] = service
+	}
+
+	if verb, ok := progressVerbs[event.EventType]; ok {
+		r.counter++
+		service.verb = verb
+		service.started = r.counter
+		service.done = false
+		service.failed = false
+		service.health = false
+	}
+
+	switch {
+	case event.EventType == projectEvents.ServiceHealthCheckStart:
+		service.health = true
+	case event.EventType == projectEvents.ServiceHealthCheck:
+		service.health = false
+	case progressDone[event.EventType]:
+		service.done = true
+		service.health = false
+		service.failed = event.EventType == projectEvents.ServiceFailed
+	}
+}
+
+// message describes the service most recently started, with the number of
+// services done, and what the services are waiting on.
+func (r *progressReporter) message() string {
+	current := ""
+	done := 0
+	health := []string{}
+	failed := []string{}
+	for name, service := range r.services {
+		if service.done {
+			done++
+		} else if service.verb != "" && (current == "" || service.started > r.services[current].started) {
+			current = name
+		}
+		if service.health {
+			health = append(health, name)
+		}
+		if service.failed {
+			failed = append(failed, name)
+		}
+	}
+
+	if current == "" {
+		return ""
+	}
+
+	total := r.total
+	if len(r.services) > total {
+		total = len(r.services)
+	}
+	message := fmt.Sprintf("%s %s (%d/%d)", r.services[current].verb, current, done, total)
+
+	if len(health) > 0 {
+		sort.Strings(health)
+		message += fmt.Sprintf(", waiting on %s health", strings.Join(health, ", "))
+	}
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		message += fmt.Sprintf(", %s failed", strings.Join(failed, ", "))
+	}
+	return message
+}
+
+// publish sends the message when it changed and the last reply is old
+// enough, the ticker sends the ones held back.
+func (r *progressReporter) publish(now time.Time) {
+	message := r.message()
+	if message == "" || message == r.published || now.Sub(r.last) < progressInterval {
+		return
+	}
+
+	publishTransitioningReply(message, r.event, r.apiClient, false)
+	r.published = message
+	r.last = now
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rancher/event-subscriber/events"
+	"github.com/rancher/go-rancher/v2"
+	"github.com/rancher/rancher-compose-executor/project"
+	projectEvents "github.com/rancher/rancher-compose-executor/project/events"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProgressReporterMessage(t *testing.T) {
+	r := &progressReporter{total: 4, services: map[string]*serviceProgress{}}
+	send := func(eventType projectEvents.EventType, service string) string {
+		r.update(projectEvents.Event{EventType: eventType, ServiceName: service})
+		return r.message()
+	}
+
+	assert.Equal(t, "", send(projectEvents.ProjectUpStart, ""))
+	assert.Equal(t, "", send(projectEvents.ServiceWaiting, "web"))
+	assert.Equal(t, "Starting db (0/4)", send(projectEvents.ServiceUpStart, "db"))
+	assert.Equal(t, "Upgrading db (0/4)", send(projectEvents.ServiceUpgradeStart, "db"))
+	assert.Equal(t, "Upgrading db (0/4), waiting on db health", send(projectEvents.ServiceHealthCheckStart, "db"))
+	assert.Equal(t, "Starting cache (0/4), waiting on db health", send(projectEvents.ServiceUpStart, "cache"))
+	assert.Equal(t, "Upgrading db (1/4), waiting on db health", send(projectEvents.ServiceUp, "cache"))
+	assert.Equal(t, "Upgrading db (1/4)", send(projectEvents.ServiceHealthCheck, "db"))
+	assert.Equal(t, "", send(projectEvents.ServiceUp, "db"))
+	assert.Equal(t, "Starting web (2/4)", send(projectEvents.ServiceUpStart, "web"))
+	assert.Equal(t, "Starting lb (2/4)", send(projectEvents.ServiceUpStart, "lb"))
+	assert.Equal(t, "Starting lb (3/4), web failed", send(projectEvents.ServiceFailed, "web"))
+	assert.Equal(t, "", send(projectEvents.ServiceUp, "lb"))
+}
+
+func TestProgressReporterPublish(t *testing.T) {
+	publish := &fakePublish{}
+	r := &progressReporter{
+		event:     &events.Event{ID: "1", ReplyTo: "reply"},
+		apiClient: &client.RancherClient{Publish: publish},
+		total:     2,
+		services:  map[string]*serviceProgress{},
+	}
+	start := time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	r.update(projectEvents.Event{EventType: projectEvents.ServiceCreateStart, ServiceName: "db"})
+	r.publish(start)
+	r.update(projectEvents.Event{EventType: projectEvents.ServiceCreate, ServiceName: "db"})
+	r.update(projectEvents.Event{EventType: projectEvents.ServiceCreateStart, ServiceName: "web"})
+	r.publish(start.Add(time.Second))
+	r.publish(start.Add(progressInterval))
+	r.publish(start.Add(2 * progressInterval))
+
+	messages := []string{}
+	for _, reply := range publish.replies {
+		assert.Equal(t, "yes", reply.Transitioning)
+		messages = append(messages, reply.TransitioningMessage)
+	}
+	assert.Equal(t, []string{"Creating db (0/2)", "Creating web (1/2)"}, messages)
+}
+
+func TestProgressReporterStop(t *testing.T) {
+	p := project.NewProject(&project.Context{})
+	r := newProgressReporter(&events.Event{}, &client.RancherClient{Publish: &fakePublish{}}, p)
+	r.stop()
+
+	notified := make(chan struct{})
+	go func() {
+		p.Notify(projectEvents.ServiceUpStart, "web", nil)
+		close(notified)
+	}()
+	select {
+	case <-notified:
+	case <-time.After(time.Second):
+		t.Fatal("Notify blocked on a stopped reporter")
+	}
+}
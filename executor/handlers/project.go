@@ -11,13 +11,13 @@ import (
 )
 
 func constructProjectUpgrade(logger *logrus.Entry, stack *client.Stack, upgradeOpts client.StackUpgrade, url, accessKey, secretKey string) (*project.Project, map[string]interface{}, error) {
-	variables, err := createVariableMap(stack, upgradeOpts.RancherCompose)
-	if err != nil {
+	if err := validateAnswers(upgradeOpts.RancherCompose, upgradeOpts.Environment, false); err != nil {
 		return nil, nil, err
 	}
 
-	for k, v := range upgradeOpts.Environment {
-		variables[k] = v
+	variables, err := createVariableMap(upgradeOpts.RancherCompose, stack.Environment, upgradeOpts.Environment)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	previousCatalogInfo, err := lookup.ParseCatalogConfig([]byte(stack.RancherCompose))
@@ -60,7 +60,7 @@ func constructProjectUpgrade(logger *logrus.Entry, stack *client.Stack, upgradeO
 }
 
 func constructProject(logger *logrus.Entry, stack *client.Stack, url, accessKey, secretKey string) (*rancher.Context, *project.Project, error) {
-	variables, err := createVariableMap(stack, stack.RancherCompose)
+	variables, err := createVariableMap(stack.RancherCompose, stack.Environment)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -86,6 +86,7 @@ func constructProject(logger *logrus.Entry, stack *client.Stack, url, accessKey,
 		Url:       fmt.Sprintf("%s/projects/%s/schemas", url, stack.AccountId),
 		AccessKey: accessKey,
 		SecretKey: secretKey,
+		Stack:     stack,
 	}
 
 	p, err := rancher.NewProject(&context)
@@ -97,10 +98,14 @@ func constructProject(logger *logrus.Entry, stack *client.Stack, url, accessKey,
 	return &context, p, nil
 }
 
-func createVariableMap(stack *client.Stack, rancherCompose string) (map[string]interface{}, error) {
+// createVariableMap merges the environments, the later ones taking
+// precedence, and fills in the defaults of the questions.
+func createVariableMap(rancherCompose string, environments ...map[string]interface{}) (map[string]interface{}, error) {
 	variables := map[string]interface{}{}
-	for k, v := range stack.Environment {
-		variables[k] = v
+	for _, environment := range environments {
+		for k, v := range environment {
+			variables[k] = v
+		}
 	}
 
 	questions, err := lookup.ParseQuestions([]byte(rancherCompose))
@@ -116,3 +121,36 @@ func createVariableMap(stack *client.Stack, rancherCompose string) (map[string]i
 
 	return variables, nil
 }
+
+// validateAnswers checks the answers of an environment against their
+// questions, with the defaults filled in. Unless all is set only the
+// questions answered in the environment are checked: the answers a stack
+// already has are not checked again, or a stack with answers that became
+// invalid could never be removed.
+func validateAnswers(rancherCompose string, environment map[string]interface{}, all bool) error {
+	questions, err := lookup.ParseQuestions([]byte(rancherCompose))
+	if err != nil {
+		return err
+	}
+
+	variables, err := createVariableMap(rancherCompose, environment)
+	if err != nil {
+		return err
+	}
+
+	answers := map[string]string{}
+	for k := range questions {
+		if _, ok := environment[k]; !ok && !all {
+			delete(questions, k)
+			continue
+		}
+		if variables[k] != nil {
+			answers[k] = fmt.Sprint(variables[k])
+		}
+	}
+
+	if err := lookup.ValidateAnswers(questions, answers); err != nil {
+		return fmt.Errorf("Invalid answers: %v", err)
+	}
+	return nil
+}
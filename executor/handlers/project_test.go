@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const questionsCompose = `
+.catalog:
+  questions:
+  - variable: NAME
+    required: true
+  - variable: PORT
+    type: int
+    default: 80
+`
+
+func TestValidateAnswers(t *testing.T) {
+	assert.NoError(t, validateAnswers(questionsCompose, map[string]interface{}{"NAME": "web"}, true))
+	assert.EqualError(t, validateAnswers(questionsCompose, map[string]interface{}{"PORT": "http"}, true),
+		`Invalid answers: Missing answers for required questions: NAME
+PORT: "http" is not an integer`)
+
+	// Only the answers given are checked, not the ones the stack already has
+	assert.NoError(t, validateAnswers(questionsCompose, map[string]interface{}{"PORT": 8080}, false))
+	assert.NoError(t, validateAnswers(questionsCompose, nil, false))
+	assert.Error(t, validateAnswers(questionsCompose, map[string]interface{}{"PORT": "http"}, false))
+}
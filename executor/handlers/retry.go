@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/pkg/errors"
+	"github.com/rancher/event-subscriber/events"
+	"github.com/rancher/go-rancher/v2"
+)
+
+// RetryPolicy sets how many times an event is handled before giving up and
+// how long to wait between the attempts. The delay doubles after each
+// attempt up to MaxBackoff, and is moved by up to Jitter of itself either
+// way so that the retries of several events do not line up.
+type RetryPolicy struct {
+	Attempts   int
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	Jitter     float64
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	Attempts:   5,
+	Backoff:    time.Second,
+	MaxBackoff: 30 * time.Second,
+	Jitter:     0.2,
+}
+
+// delay returns the time to wait after the given attempt, starting at 1.
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	delay := p.Backoff
+	for i := 1; i < attempt && delay < p.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+	if p.Jitter > 0 {
+		delay += time.Duration((rand.Float64()*2 - 1) * p.Jitter * float64(delay))
+	}
+	return delay
+}
+
+// WithRetry handles the event again while it fails with a retryable error,
+// the last error is sent as an error reply and returned to the router.
+func WithRetry(policy RetryPolicy, f events.EventHandler) events.EventHandler {
+	return func(event *events.Event, apiClient *client.RancherClient) error {
+		logger := logrus.WithFields(logrus.Fields{
+			"resourceId": event.ResourceID,
+			"eventId":    event.ID,
+			"eventName":  event.Name,
+		})
+
+		var err error
+		for attempt := 1; ; attempt++ {
+			logger.Infof("Attempt %d of %d", attempt, policy.Attempts)
+			if err = f(event, apiClient); err == nil {
+				return nil
+			}
+
+			if !IsRetryable(err) {
+				logger.Errorf("Attempt %d of %d failed, not retrying: %v", attempt, policy.Attempts, err)
+				break
+			}
+			if attempt >= policy.Attempts {
+				logger.Errorf("Attempt %d of %d failed, giving up: %v", attempt, policy.Attempts, err)
+				break
+			}
+
+			delay := policy.delay(attempt)
+			logger.Warnf("Attempt %d of %d failed, retrying in %v: %v", attempt, policy.Attempts, delay, err)
+			time.Sleep(delay)
+		}
+
+		publishTransitioningReply(err.Error(), event, apiClient, true)
+		return err
+	}
+}
+
+// transientMessages are matched against the errors that lost their type on
+// the way up, the rancher package formats most API errors into strings.
+var transientMessages = []string{
+	"connection reset by peer",
+	"connection refused",
+	"broken pipe",
+	"i/o timeout",
+	"unexpected EOF",
+}
+
+// IsRetryable reports whether handling the event again may succeed: server
+// errors, throttling, dropped connections and ErrTimeout. Client errors and
+// any other error, like an invalid compose file, are permanent.
+func IsRetryable(err error) bool {
+	cause := errors.Cause(err)
+
+	if apiError, ok := cause.(*client.ApiError); ok {
+		return apiError.StatusCode >= 500 || apiError.StatusCode == http.StatusTooManyRequests
+	}
+
+	if netError, ok := cause.(net.Error); ok && netError.Timeout() {
+		return true
+	}
+
+	switch rootCause(err) {
+	case ErrTimeout, syscall.ECONNRESET, syscall.ECONNREFUSED, syscall.EPIPE, io.EOF, io.ErrUnexpectedEOF:
+		return true
+	}
+
+	message := err.Error()
+	for _, transient := range transientMessages {
+		if strings.Contains(message, transient) {
+			return true
+		}
+	}
+	return false
+}
+
+// rootCause unwraps the errors of github.com/pkg/errors and the errors of the
+// net, net/url and os packages down to the error that caused them.
+func rootCause(err error) error {
+	for {
+		switch cause := errors.Cause(err).(type) {
+		case *url.Error:
+			err = cause.Err
+		case *net.OpError:
+			err = cause.Err
+		case *os.SyscallError:
+			err = cause.Err
+		default:
+			return cause
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/rancher/event-subscriber/events"
+	"github.com/rancher/go-rancher/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakePublish struct {
+	client.PublishOperations
+	replies []*client.Publish
+}
+
+func (f *fakePublish) Create(publish *client.Publish) (*client.Publish, error) {
+	f.replies = append(f.replies, publish)
+	return publish, nil
+}
+
+func TestIsRetryable(t *testing.T) {
+	reset := &url.Error{
+		Op:  "Get",
+		URL: "http://rancher/v2-beta",
+		Err: &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET},
+	}
+
+	for err, retryable := range map[error]bool{
+		ErrTimeout:                                          true,
+		&client.ApiError{StatusCode: 503}:                   true,
+		&client.ApiError{StatusCode: 429}:                   true,
+		&client.ApiError{StatusCode: 422}:                   false,
+		&client.ApiError{StatusCode: 404}:                   false,
+		reset:                                               true,
+		fmt.Errorf("Failed to create web: %v", reset):       true,
+		errors.New("Invalid answers: PORT: not an integer"): false,
+	} {
+		assert.Equal(t, retryable, IsRetryable(err), err.Error())
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := RetryPolicy{Backoff: time.Second, MaxBackoff: 5 * time.Second}
+	assert.Equal(t, time.Second, policy.delay(1))
+	assert.Equal(t, 2*time.Second, policy.delay(2))
+	assert.Equal(t, 4*time.Second, policy.delay(3))
+	assert.Equal(t, 5*time.Second, policy.delay(4))
+
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		delay := policy.delay(2)
+		assert.True(t, delay >= time.Second && delay <= 3*time.Second, delay.String())
+	}
+}
+
+func TestWithRetry(t *testing.T) {
+	policy := RetryPolicy{Attempts: 3}
+	event := &events.Event{ID: "1", ReplyTo: "reply.1"}
+
+	for _, test := range []struct {
+		errors   []error
+		attempts int
+		err      error
+	}{
+		{[]error{nil}, 1, nil},
+		{[]error{&client.ApiError{StatusCode: 503}, &client.ApiError{StatusCode: 502}, nil}, 3, nil},
+		{[]error{io.EOF, io.EOF, io.EOF, nil}, 3, io.EOF},
+		{[]error{ErrTimeout, ErrTimeout, ErrTimeout, nil}, 3, ErrTimeout},
+		{[]error{&client.ApiError{StatusCode: 502, Msg: "Bad gateway"}, errors.New("Bad compose file"), nil}, 2, errors.New("Bad compose file")},
+	} {
+		publish := &fakePublish{}
+		attempts := 0
+		handler := WithRetry(policy, func(*events.Event, *client.RancherClient) error {
+			err := test.errors[attempts]
+			attempts++
+			return err
+		})
+
+		err := handler(event, &client.RancherClient{Publish: publish})
+		assert.Equal(t, test.err, err)
+		assert.Equal(t, test.attempts, attempts)
+
+		if test.err == nil {
+			assert.Empty(t, publish.replies)
+		} else if assert.Len(t, publish.replies, 1) {
+			assert.Equal(t, "error", publish.replies[0].Transitioning)
+			assert.Equal(t, test.err.Error(), publish.replies[0].TransitioningMessage)
+			assert.Equal(t, []string{"1"}, publish.replies[0].PreviousIds)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/rancher/event-subscriber/events"
+	"github.com/rancher/go-rancher/v2"
+)
+
+// Serializer runs the events of one resource one after another, in the order
+// they reach it, while the events of different resources run in parallel.
+// It also remembers the last events handled successfully with their replies
+// so that an event delivered again is answered without being handled twice.
+type Serializer struct {
+	sync.Mutex
+	queues    map[string][]chan struct{}
+	completed *eventLRU
+	// queued is called once an event waits for the events of its resource
+	queued func(key string)
+}
+
+func NewSerializer(size int) *Serializer {
+	return &Serializer{
+		queues:    map[string][]chan struct{}{},
+		completed: newEventLRU(size),
+	}
+}
+
+// Wrap serializes the handler, events without a resource are not serialized.
+func (s *Serializer) Wrap(f events.EventHandler) events.EventHandler {
+	return func(event *events.Event, apiClient *client.RancherClient) error {
+		key := ""
+		if event.ResourceID != "" {
+			key = fmt.Sprintf("%s:%s", event.ResourceType, event.ResourceID)
+			s.acquire(key)
+			defer s.release(key)
+		}
+
+		if reply, ok := s.completedReply(event.ID); ok {
+			logrus.WithFields(logrus.Fields{
+				"resourceId": event.ResourceID,
+				"eventId":    event.ID,
+				"eventName":  event.Name,
+			}).Info("Event already handled, replying again")
+			if reply == nil {
+				return emptyReply(event, apiClient)
+			}
+			return publishReply(reply, apiClient)
+		}
+
+		recorder := &replyRecorder{PublishOperations: apiClient.Publish}
+		recording := *apiClient
+		recording.Publish = recorder
+		if err := f(event, &recording); err != nil {
+			return err
+		}
+
+		s.Lock()
+		s.completed.add(event.ID, recorder.reply)
+		s.Unlock()
+		return nil
+	}
+}
+
+func (s *Serializer) completedReply(id string) (*client.Publish, bool) {
+	s.Lock()
+	defer s.Unlock()
+	return s.completed.get(id)
+}
+
+// replyRecorder keeps the last final reply published by a handler, replies
+// that only report progress are not kept.
+type replyRecorder struct {
+	client.PublishOperations
+	reply *client.Publish
+}
+
+func (r *replyRecorder) Create(publish *client.Publish) (*client.Publish, error) {
+	created, err := r.PublishOperations.Create(publish)
+	if err == nil && publish.Transitioning == "" {
+		r.reply = publish
+	}
+	return created, err
+}
+
+// acquire waits for the events of the resource that came before, the queue
+// of a resource holds one channel per waiting event and is removed once the
+// resource is idle.
+func (s *Serializer) acquire(key string) {
+	s.Lock()
+	waiting, running := s.queues[key]
+	if !running {
+		s.queues[key] = nil
+		s.Unlock()
+		return
+	}
+	turn := make(chan struct{})
+	s.queues[key] = append(waiting, turn)
+	s.Unlock()
+
+	if s.queued != nil {
+		s.queued(key)
+	}
+	<-turn
+}
+
+func (s *Serializer) release(key string) {
+	s.Lock()
+	defer s.Unlock()
+
+	waiting := s.queues[key]
+	if len(waiting) == 0 {
+		delete(s.queues, key)
+		return
+	}
+	s.queues[key] = waiting[1:]
+	close(waiting[0])
+}
+
+// eventLRU maps event IDs to their replies and forgets the least recently
+// added ones past its size.
+type eventLRU struct {
+	size    int
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type eventEntry struct {
+	id    string
+	reply *client.Publish
+}
+
+func newEventLRU(size int) *eventLRU {
+	return &eventLRU{
+		size:    size,
+		order:   list.New(),
+		entries: map[string]*list.Element{},
+	}
+}
+
+func (l *eventLRU) add(id string, reply *client.Publish) {
+	if element, ok := l.entries[id]; ok {
+		element.Value.(*eventEntry).reply = reply
+		l.order.MoveToFront(element)
+		return
+	}
+
+	l.entries[id] = l.order.PushFront(&eventEntry{id: id, reply: reply})
+	for l.order.Len() > l.size {
+		oldest := l.order.Back()
+		l.order.Remove(oldest)
+		delete(l.entries, oldest.Value.(*eventEntry).id)
+	}
+}
+
+func (l *eventLRU) get(id string) (*client.Publish, bool) {
+	element, ok := l.entries[id]
+	if !ok {
+		return nil, false
+	}
+	return element.Value.(*eventEntry).reply, true
+}
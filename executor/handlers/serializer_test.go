@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/rancher/event-subscriber/events"
+	"github.com/rancher/go-rancher/v2"
+	"github.com/rancher/rancher-compose-executor/executor/metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSerializerOrder(t *testing.T) {
+	s := NewSerializer(10)
+	apiClient := &client.RancherClient{Publish: &fakePublish{}}
+
+	queued := make(chan string)
+	s.queued = func(key string) {
+		queued <- key
+	}
+
+	var lock sync.Mutex
+	running := map[string]int{}
+	overlap := false
+
+	started := make(chan string)
+	done := map[string]chan struct{}{}
+	for _, id := range []string{"upgrade", "create", "rollback", "finishupgrade"} {
+		done[id] = make(chan struct{})
+	}
+
+	handler := s.Wrap(func(event *events.Event, apiClient *client.RancherClient) error {
+		lock.Lock()
+		if running[event.ResourceID]++; running[event.ResourceID] > 1 {
+			overlap = true
+		}
+		lock.Unlock()
+
+		started <- event.ID
+		<-done[event.ID]
+
+		lock.Lock()
+		running[event.ResourceID]--
+		lock.Unlock()
+		return nil
+	})
+
+	wg := sync.WaitGroup{}
+	handle := func(id, resourceID string) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, handler(&events.Event{ID: id, ResourceType: "stack", ResourceID: resourceID}, apiClient))
+		}()
+	}
+
+	// Both stacks run at the same time
+	handle("upgrade", "1st1")
+	assert.Equal(t, "upgrade", <-started)
+	handle("create", "1st2")
+	assert.Equal(t, "create", <-started)
+
+	// The later events of the first stack wait for it in order
+	handle("rollback", "1st1")
+	assert.Equal(t, "stack:1st1", <-queued)
+	handle("finishupgrade", "1st1")
+	assert.Equal(t, "stack:1st1", <-queued)
+
+	close(done["create"])
+	close(done["upgrade"])
+	assert.Equal(t, "rollback", <-started)
+	close(done["rollback"])
+	assert.Equal(t, "finishupgrade", <-started)
+	close(done["finishupgrade"])
+	wg.Wait()
+
+	assert.False(t, overlap, "events of one stack ran at the same time")
+	assert.Empty(t, s.queues)
+}
+
+func TestSerializerRedelivery(t *testing.T) {
+	s := NewSerializer(10)
+	publish := &fakePublish{}
+	apiClient := &client.RancherClient{
+		Publish: publish,
+		Stack:   &fakeStacks{stacks: map[string]*client.Stack{}},
+	}
+	handler := s.Wrap(WithRetry(RetryPolicy{Attempts: 1}, RemoveStack))
+
+	event := &events.Event{ID: "1", ReplyTo: "reply", ResourceType: "stack", ResourceID: "1st1"}
+	assert.NoError(t, handler(event, apiClient))
+	assert.NoError(t, handler(event, apiClient))
+	if assert.Len(t, publish.replies, 2) {
+		assert.Equal(t, publish.replies[0], publish.replies[1])
+	}
+
+	// The data of the reply is sent again, progress is not
+	publish.replies = nil
+	handled := 0
+	replying := s.Wrap(func(event *events.Event, apiClient *client.RancherClient) error {
+		handled++
+		publishTransitioningReply("Rolling back", event, apiClient, false)
+		return reply(event, apiClient, map[string]interface{}{"externalId": "catalog://web:1"})
+	})
+	event = &events.Event{ID: "2", ReplyTo: "reply", ResourceType: "stack", ResourceID: "1st1"}
+	assert.NoError(t, replying(event, apiClient))
+	assert.NoError(t, replying(event, apiClient))
+	assert.Equal(t, 1, handled)
+	if assert.Len(t, publish.replies, 3) {
+		assert.Equal(t, "yes", publish.replies[0].Transitioning)
+		assert.Equal(t, publish.replies[1], publish.replies[2])
+		assert.Equal(t, "catalog://web:1", publish.replies[2].Data["externalId"])
+	}
+
+	// Events handled without a final reply are answered with an empty one
+	publish.replies = nil
+	silent := s.Wrap(func(event *events.Event, apiClient *client.RancherClient) error {
+		return nil
+	})
+	event = &events.Event{ID: "3", ReplyTo: "reply", ResourceType: "stack", ResourceID: "1st1"}
+	assert.NoError(t, silent(event, apiClient))
+	assert.NoError(t, silent(event, apiClient))
+	if assert.Len(t, publish.replies, 1) {
+		assert.Equal(t, []string{"3"}, publish.replies[0].PreviousIds)
+	}
+
+	// Failed events are handled again
+	failures := 0
+	failing := s.Wrap(func(event *events.Event, apiClient *client.RancherClient) error {
+		failures++
+		return errors.New("Bad compose file")
+	})
+	event = &events.Event{ID: "4", ResourceType: "stack", ResourceID: "1st1"}
+	assert.Error(t, failing(event, apiClient))
+	assert.Error(t, failing(event, apiClient))
+	assert.Equal(t, 2, failures)
+}
+
+func TestSerializerMetrics(t *testing.T) {
+	s := NewSerializer(10)
+	apiClient := &client.RancherClient{Publish: &fakePublish{}}
+	handler := s.Wrap(metrics.Instrument("test.serialized", func(event *events.Event, apiClient *client.RancherClient) error {
+		return emptyReply(event, apiClient)
+	}))
+
+	event := &events.Event{ID: "1", ReplyTo: "reply", ResourceType: "stack", ResourceID: "1st1"}
+	assert.NoError(t, handler(event, apiClient))
+	assert.NoError(t, handler(event, apiClient))
+
+	recorder := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	lines := strings.Split(recorder.Body.String(), "\n")
+	assert.Contains(t, lines, `rancher_compose_executor_events_received_total{event="test.serialized"} 1`)
+	assert.Contains(t, lines, `rancher_compose_executor_events_succeeded_total{event="test.serialized"} 1`)
+}
+
+func TestEventLRU(t *testing.T) {
+	l := newEventLRU(2)
+	reply := &client.Publish{Name: "reply"}
+	l.add("1", nil)
+	l.add("2", nil)
+	l.add("1", reply)
+	l.add("3", nil)
+
+	got, ok := l.get("1")
+	assert.True(t, ok)
+	assert.Equal(t, reply, got)
+	_, ok = l.get("2")
+	assert.False(t, ok)
+	_, ok = l.get("3")
+	assert.True(t, ok)
+}
@@ -23,8 +23,6 @@ func UpgradeStack(event *events.Event, apiClient *client.RancherClient) error {
 	logger.Info("Upgrade Stack Event Received")
 
 	if err := upgradeEnvironment(logger, event, apiClient); err != nil {
-		logger.Errorf("Stack Upgrade Event Failed: %v", err)
-		publishTransitioningReply(err.Error(), event, apiClient, true)
 		return err
 	}
 
@@ -131,6 +129,12 @@ func RollbackStack(event *events.Event, apiClient *client.RancherClient) error {
 	})
 }
 
+var waitInterval = 500 * time.Millisecond
+
+// wait polls the service for a few seconds, the router worker is not held
+// longer. A service still transitioning gives ErrTimeout, which WithRetry
+// retries: the event is handled again and waits for the service before
+// acting on it.
 func wait(apiClient *client.RancherClient, service *client.Service) error {
 	for i := 0; i < 6; i++ {
 		if err := apiClient.Reload(&service.Resource, service); err != nil {
@@ -139,7 +143,7 @@ func wait(apiClient *client.RancherClient, service *client.Service) error {
 		if service.Transitioning != "yes" {
 			break
 		}
-		time.Sleep(500 * time.Millisecond)
+		time.Sleep(waitInterval)
 	}
 
 	switch service.Transitioning {
@@ -179,6 +183,8 @@ func upgradeEnvironment(logger *logrus.Entry, event *events.Event, apiClient *cl
 	}
 
 	publishTransitioningReply("Upgrading stack", event, apiClient, false)
+	reporter := newProgressReporter(event, apiClient, project)
+	defer reporter.stop()
 
 	if err := project.Up(context.Background(), options.Up{}); err != nil {
 		return err
@@ -194,6 +200,7 @@ func upgradeEnvironment(logger *logrus.Entry, event *events.Event, apiClient *cl
 		previousEnv = stack.Environment
 	}
 
+	reporter.stop()
 	return reply(event, apiClient, map[string]interface{}{
 		"externalId":          upgradeOpts.ExternalId,
 		"environment":         newEnv,
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rancher/go-rancher/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWait(t *testing.T) {
+	defer func(interval time.Duration) {
+		waitInterval = interval
+	}(waitInterval)
+	waitInterval = time.Millisecond
+
+	reloads := 0
+	states := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		state := states[len(states)-1]
+		if reloads < len(states) {
+			state = states[reloads]
+		}
+		reloads++
+		fmt.Fprintf(w, `{"id": "1s1", "name": "web", "transitioning": %q, "transitioningMessage": "Bad image"}`, state)
+	}))
+	defer server.Close()
+
+	apiClient := &client.RancherClient{
+		RancherBaseClient: &client.RancherBaseClientImpl{Opts: &client.ClientOpts{Url: server.URL}},
+	}
+
+	for _, test := range []struct {
+		states  []string
+		reloads int
+		err     string
+	}{
+		{[]string{"no"}, 1, ""},
+		{[]string{"yes", "yes", "yes", "no"}, 4, ""},
+		{[]string{"yes", "error"}, 2, "Waiting for web failed: Bad image"},
+		{[]string{"yes"}, 6, ErrTimeout.Error()},
+	} {
+		reloads, states = 0, test.states
+
+		service := &client.Service{Resource: client.Resource{Links: map[string]string{"self": server.URL}}}
+		err := wait(apiClient, service)
+		if test.err == "" {
+			assert.NoError(t, err)
+		} else {
+			assert.EqualError(t, err, test.err)
+		}
+		assert.Equal(t, test.reloads, reloads)
+	}
+}
@@ -1,31 +1,66 @@
 package executor
 
 import (
+	"flag"
+	"net/http"
 	"os"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/rancher/event-subscriber/events"
 	"github.com/rancher/go-rancher/v2"
 	"github.com/rancher/rancher-compose-executor/executor/handlers"
+	"github.com/rancher/rancher-compose-executor/executor/metrics"
 	"github.com/rancher/rancher-compose-executor/version"
 )
 
+// eventHistorySize is the number of handled events whose replies are kept to
+// answer the events that are delivered again.
+const eventHistorySize = 1000
+
 func Main() {
+	flags := flag.NewFlagSet("rancher-compose-executor", flag.ExitOnError)
+	metricsAddr := flags.String("metrics-addr", "", "Address to serve /healthz and /metrics on, like :9090 (default: disabled)")
+	flags.Parse(os.Args[1:])
+
 	logger := logrus.WithFields(logrus.Fields{
 		"version": version.VERSION,
 	})
 
 	logger.Info("Starting rancher-compose-executor")
 
+	if *metricsAddr != "" {
+		http.DefaultTransport = metrics.InstrumentTransport(http.DefaultTransport)
+		go func() {
+			logger.Infof("Serving metrics on %s", *metricsAddr)
+			if err := metrics.ListenAndServe(*metricsAddr); err != nil {
+				logrus.WithField("error", err).Fatal("Unable to serve metrics")
+			}
+		}()
+	}
+
+	// Events of one stack run in order, with the retries of an event before
+	// the next event. The metrics leave out the time an event waits for the
+	// events before it and the events delivered again after they succeeded.
+	serializer := handlers.NewSerializer(eventHistorySize)
+	stackHandlers := map[string]events.EventHandler{
+		"stack.create":        handlers.CreateStack,
+		"stack.upgrade":       handlers.UpgradeStack,
+		"stack.finishupgrade": handlers.FinishUpgradeStack,
+		"stack.rollback":      handlers.RollbackStack,
+		"stack.remove":        handlers.RemoveStack,
+		"stack.activate":      handlers.ActivateStack,
+		"stack.deactivate":    handlers.DeactivateStack,
+	}
+
 	eventHandlers := map[string]events.EventHandler{
-		"stack.create":        handlers.WithTimeout(handlers.CreateStack),
-		"stack.upgrade":       handlers.WithTimeout(handlers.UpgradeStack),
-		"stack.finishupgrade": handlers.WithTimeout(handlers.FinishUpgradeStack),
-		"stack.rollback":      handlers.WithTimeout(handlers.RollbackStack),
 		"ping": func(event *events.Event, apiClient *client.RancherClient) error {
+			metrics.Ping()
 			return nil
 		},
 	}
+	for name, handler := range stackHandlers {
+		eventHandlers[name] = serializer.Wrap(metrics.Instrument(name, handlers.WithRetry(handlers.DefaultRetryPolicy, handler)))
+	}
 
 	router, err := events.NewEventRouter("rancher-compose-executor", 2000,
 		os.Getenv("CATTLE_URL"),
@@ -36,7 +71,15 @@ func Main() {
 		logrus.WithField("error", err).Fatal("Unable to create event router")
 	}
 
-	if err := router.Start(nil); err != nil {
+	ready := make(chan bool, 1)
+	go func() {
+		<-ready
+		metrics.Connected(true)
+	}()
+
+	err = router.Start(ready)
+	metrics.Connected(false)
+	if err != nil {
 		logrus.WithField("error", err).Fatal("Unable to start event router")
 	}
 
@@ -0,0 +1,83 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/rancher/event-subscriber/events"
+	"github.com/rancher/go-rancher/v2"
+)
+
+const prefix = "rancher_compose_executor_"
+
+var (
+	eventBuckets = []float64{0.1, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300, 600}
+	apiBuckets   = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+	metrics = newRegistry()
+
+	eventsReceived  = metrics.counter(prefix+"events_received_total", "Events received, by event name, not counting the events delivered again after they succeeded.", "event")
+	eventsSucceeded = metrics.counter(prefix+"events_succeeded_total", "Events handled successfully, by event name.", "event")
+	eventsFailed    = metrics.counter(prefix+"events_failed_total", "Events whose handler failed, by event name.", "event")
+	eventsInFlight  = metrics.gauge(prefix+"events_in_flight", "Events being handled, by event name, not counting the events waiting for their stack.", "event")
+	eventDuration   = metrics.histogram(prefix+"event_duration_seconds", "Time to handle an event, retries included and waiting for its stack excluded, by event name.", eventBuckets, "event")
+
+	apiRequests        = metrics.counter(prefix+"api_requests_total", "Requests to the Rancher API, by method and status code.", "method", "code")
+	apiRequestDuration = metrics.histogram(prefix+"api_request_duration_seconds", "Time of the requests to the Rancher API, by method.", apiBuckets, "method")
+
+	_ = metrics.gaugeFunc(prefix+"event_stream_connected", "Whether the event stream of Rancher is connected.", func() float64 {
+		if connected, _ := health.status(time.Now()); connected {
+			return 1
+		}
+		return 0
+	})
+	_ = metrics.gaugeFunc(prefix+"last_ping_age_seconds", "Time since the last ping of Rancher, or since connecting before the first one.", func() float64 {
+		_, age := health.status(time.Now())
+		return age.Seconds()
+	})
+)
+
+// Instrument counts the events of the handler and times them.
+func Instrument(name string, f events.EventHandler) events.EventHandler {
+	return func(event *events.Event, apiClient *client.RancherClient) error {
+		eventsReceived.add(1, name)
+		eventsInFlight.add(1, name)
+		start := time.Now()
+
+		err := f(event, apiClient)
+
+		eventDuration.observe(time.Since(start).Seconds(), name)
+		eventsInFlight.add(-1, name)
+		if err != nil {
+			eventsFailed.add(1, name)
+		} else {
+			eventsSucceeded.add(1, name)
+		}
+		return err
+	}
+}
+
+type transport struct {
+	http.RoundTripper
+}
+
+// InstrumentTransport counts and times the requests of the transport, the
+// clients of go-rancher use the default transport of net/http.
+func InstrumentTransport(rt http.RoundTripper) http.RoundTripper {
+	return &transport{rt}
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.RoundTripper.RoundTrip(req)
+
+	apiRequestDuration.observe(time.Since(start).Seconds(), req.Method)
+	code := "error"
+	if err == nil {
+		code = strconv.Itoa(resp.StatusCode)
+	}
+	apiRequests.add(1, req.Method, code)
+
+	return resp, err
+}
@@ -0,0 +1,132 @@
+package metrics
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rancher/event-subscriber/events"
+	"github.com/rancher/go-rancher/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistryWriteTo(t *testing.T) {
+	r := newRegistry()
+	counter := r.counter("events_total", "Events, by name.", "event")
+	histogram := r.histogram("duration_seconds", "Duration.", []float64{1, 5}, "event")
+	r.gaugeFunc("up", "Up.", func() float64 { return 1 })
+
+	counter.add(1, "stack.create")
+	counter.add(2, `a"b`)
+	histogram.observe(0.5, "stack.create")
+	histogram.observe(3, "stack.create")
+
+	buffer := &bytes.Buffer{}
+	r.WriteTo(buffer)
+	assert.Equal(t, `# HELP events_total Events, by name.
+# TYPE events_total counter
+events_total{event="a\"b"} 2
+events_total{event="stack.create"} 1
+# HELP duration_seconds Duration.
+# TYPE duration_seconds histogram
+duration_seconds_bucket{event="stack.create",le="1"} 1
+duration_seconds_bucket{event="stack.create",le="5"} 2
+duration_seconds_bucket{event="stack.create",le="+Inf"} 2
+duration_seconds_sum{event="stack.create"} 3.5
+duration_seconds_count{event="stack.create"} 2
+# HELP up Up.
+# TYPE up gauge
+up 1
+`, buffer.String())
+}
+
+func TestInstrument(t *testing.T) {
+	handler := Instrument("test.event", func(event *events.Event, apiClient *client.RancherClient) error {
+		if event.ID == "bad" {
+			return errors.New("Bad event")
+		}
+		return nil
+	})
+
+	assert.NoError(t, handler(&events.Event{ID: "good"}, nil))
+	assert.Error(t, handler(&events.Event{ID: "bad"}, nil))
+	assert.NoError(t, handler(&events.Event{ID: "good"}, nil))
+
+	server := httptest.NewServer(Handler())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/metrics")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer resp.Body.Close()
+	body, _ := ioutil.ReadAll(resp.Body)
+
+	for _, line := range []string{
+		`rancher_compose_executor_events_received_total{event="test.event"} 3`,
+		`rancher_compose_executor_events_succeeded_total{event="test.event"} 2`,
+		`rancher_compose_executor_events_failed_total{event="test.event"} 1`,
+		`rancher_compose_executor_events_in_flight{event="test.event"} 0`,
+		`rancher_compose_executor_event_duration_seconds_count{event="test.event"} 3`,
+	} {
+		assert.Contains(t, strings.Split(string(body), "\n"), line)
+	}
+}
+
+func TestInstrumentTransport(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer api.Close()
+
+	c := &http.Client{Transport: InstrumentTransport(http.DefaultTransport)}
+	resp, err := c.Get(api.URL)
+	if assert.NoError(t, err) {
+		resp.Body.Close()
+	}
+
+	buffer := &bytes.Buffer{}
+	metrics.WriteTo(buffer)
+	assert.Contains(t, buffer.String(), `rancher_compose_executor_api_requests_total{method="GET",code="404"} 1`)
+	assert.Contains(t, buffer.String(), `rancher_compose_executor_api_request_duration_seconds_count{method="GET"} 1`)
+}
+
+func TestHealth(t *testing.T) {
+	defer func(maxPingAge time.Duration) {
+		MaxPingAge = maxPingAge
+	}(MaxPingAge)
+
+	check := func() (int, healthReply) {
+		recorder := httptest.NewRecorder()
+		Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/healthz", nil))
+		reply := healthReply{}
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &reply))
+		return recorder.Code, reply
+	}
+
+	Connected(false)
+	code, reply := check()
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.False(t, reply.Connected)
+
+	Connected(true)
+	code, reply = check()
+	assert.Equal(t, http.StatusOK, code)
+	assert.True(t, reply.Healthy)
+
+	MaxPingAge = 10 * time.Millisecond
+	time.Sleep(20 * time.Millisecond)
+	code, reply = check()
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.True(t, reply.Connected)
+
+	Ping()
+	code, _ = check()
+	assert.Equal(t, http.StatusOK, code)
+}
@@ -0,0 +1,184 @@
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// family is a metric with all its label values, written in the text format
+// of Prometheus. Counters and gauges keep one value per series, histograms
+// keep the counts of their buckets.
+type family struct {
+	sync.Mutex
+	name    string
+	help    string
+	kind    string
+	labels  []string
+	buckets []float64
+	value   func() float64
+	series  map[string]*series
+}
+
+type series struct {
+	labelValues []string
+	value       float64
+	counts      []uint64
+	sum         float64
+	count       uint64
+}
+
+// registry holds the families in the order they were added.
+type registry struct {
+	sync.Mutex
+	families []*family
+}
+
+func newRegistry() *registry {
+	return &registry{}
+}
+
+func (r *registry) add(f *family) *family {
+	r.Lock()
+	defer r.Unlock()
+	f.series = map[string]*series{}
+	r.families = append(r.families, f)
+	return f
+}
+
+func (r *registry) counter(name, help string, labels ...string) *family {
+	return r.add(&family{name: name, help: help, kind: "counter", labels: labels})
+}
+
+func (r *registry) gauge(name, help string, labels ...string) *family {
+	return r.add(&family{name: name, help: help, kind: "gauge", labels: labels})
+}
+
+// gaugeFunc adds a gauge without labels whose value is read when written.
+func (r *registry) gaugeFunc(name, help string, value func() float64) *family {
+	return r.add(&family{name: name, help: help, kind: "gauge", value: value})
+}
+
+func (r *registry) histogram(name, help string, buckets []float64, labels ...string) *family {
+	return r.add(&family{name: name, help: help, kind: "histogram", labels: labels, buckets: buckets})
+}
+
+func (f *family) get(labelValues []string) *series {
+	key := strings.Join(labelValues, "\xff")
+	s, ok := f.series[key]
+	if !ok {
+		s = &series{
+			labelValues: labelValues,
+			counts:      make([]uint64, len(f.buckets)),
+		}
+		f.series[key] = s
+	}
+	return s
+}
+
+func (f *family) add(value float64, labelValues ...string) {
+	f.Lock()
+	defer f.Unlock()
+	f.get(labelValues).value += value
+}
+
+func (f *family) observe(value float64, labelValues ...string) {
+	f.Lock()
+	defer f.Unlock()
+	s := f.get(labelValues)
+	for i, bucket := range f.buckets {
+		if value <= bucket {
+			s.counts[i]++
+		}
+	}
+	s.sum += value
+	s.count++
+}
+
+// WriteTo writes the families in the text format of Prometheus, the series
+// of a family are sorted by their label values.
+func (r *registry) WriteTo(w io.Writer) (int64, error) {
+	r.Lock()
+	families := append([]*family{}, r.families...)
+	r.Unlock()
+
+	buffer := &bytes.Buffer{}
+	for _, f := range families {
+		f.write(buffer)
+	}
+	return buffer.WriteTo(w)
+}
+
+func (f *family) write(w *bytes.Buffer) {
+	f.Lock()
+	defer f.Unlock()
+
+	fmt.Fprintf(w, "# HELP %s %s\n", f.name, escape(f.help, false))
+	fmt.Fprintf(w, "# TYPE %s %s\n", f.name, f.kind)
+
+	if f.value != nil {
+		fmt.Fprintf(w, "%s %s\n", f.name, formatFloat(f.value()))
+		return
+	}
+
+	keys := make([]string, 0, len(f.series))
+	for key := range f.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		s := f.series[key]
+		if f.kind != "histogram" {
+			fmt.Fprintf(w, "%s%s %s\n", f.name, f.formatLabels(s.labelValues, ""), formatFloat(s.value))
+			continue
+		}
+
+		for i, bucket := range f.buckets {
+			fmt.Fprintf(w, "%s_bucket%s %d\n", f.name, f.formatLabels(s.labelValues, formatFloat(bucket)), s.counts[i])
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", f.name, f.formatLabels(s.labelValues, "+Inf"), s.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", f.name, f.formatLabels(s.labelValues, ""), formatFloat(s.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", f.name, f.formatLabels(s.labelValues, ""), s.count)
+	}
+}
+
+func (f *family) formatLabels(values []string, le string) string {
+	pairs := []string{}
+	for i, label := range f.labels {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, label, escape(values[i], true)))
+	}
+	if le != "" {
+		pairs = append(pairs, fmt.Sprintf(`le="%s"`, le))
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func escape(value string, quotes bool) string {
+	value = strings.Replace(value, `\`, `\\`, -1)
+	value = strings.Replace(value, "\n", `\n`, -1)
+	if quotes {
+		value = strings.Replace(value, `"`, `\"`, -1)
+	}
+	return value
+}
+
+func formatFloat(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
@@ -0,0 +1,85 @@
+package metrics
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// MaxPingAge is the longest time without a ping from Rancher before the
+// executor is reported unhealthy.
+var MaxPingAge = 2 * time.Minute
+
+var health = &healthState{}
+
+type healthState struct {
+	sync.Mutex
+	connected bool
+	since     time.Time
+}
+
+// status returns whether the event stream is connected and the time since
+// the last ping, or since connecting when there was none yet.
+func (h *healthState) status(now time.Time) (bool, time.Duration) {
+	h.Lock()
+	defer h.Unlock()
+	if !h.connected {
+		return false, 0
+	}
+	return true, now.Sub(h.since)
+}
+
+// Connected records that the event stream is connected or closed.
+func Connected(connected bool) {
+	health.Lock()
+	defer health.Unlock()
+	health.connected = connected
+	health.since = time.Now()
+}
+
+// Ping records a ping from Rancher.
+func Ping() {
+	health.Lock()
+	defer health.Unlock()
+	health.since = time.Now()
+}
+
+type healthReply struct {
+	Healthy            bool    `json:"healthy"`
+	Connected          bool    `json:"connected"`
+	LastPingAgeSeconds float64 `json:"lastPingAgeSeconds"`
+}
+
+func serveHealth(w http.ResponseWriter, r *http.Request) {
+	connected, age := health.status(time.Now())
+	reply := healthReply{
+		Healthy:            connected && age <= MaxPingAge,
+		Connected:          connected,
+		LastPingAgeSeconds: age.Seconds(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if !reply.Healthy {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(reply)
+}
+
+func serveMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	metrics.WriteTo(w)
+}
+
+// Handler serves /healthz and /metrics.
+func Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", serveHealth)
+	mux.HandleFunc("/metrics", serveMetrics)
+	return mux
+}
+
+// ListenAndServe serves /healthz and /metrics on the address.
+func ListenAndServe(addr string) error {
+	return http.ListenAndServe(addr, Handler())
+}
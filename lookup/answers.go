@@ -0,0 +1,151 @@
+package lookup
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/rancher/rancher-catalog-service/model"
+	"gopkg.in/yaml.v2"
+)
+
+// ParseAnswers reads the answers to the questions of a catalog template from
+// a YAML or JSON file mapping each variable to its value.
+func ParseAnswers(file string) (map[string]string, error) {
+	contents, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var data map[string]interface{}
+	if err := yaml.Unmarshal(contents, &data); err != nil {
+		return nil, fmt.Errorf("Failed to parse answers file %s: %v", file, err)
+	}
+
+	answers := map[string]string{}
+	for key, value := range data {
+		switch value.(type) {
+		case nil:
+			answers[key] = ""
+		case map[interface{}]interface{}, []interface{}:
+			return nil, fmt.Errorf("Invalid answer for %s in %s, expected a single value", key, file)
+		default:
+			answers[key] = fmt.Sprint(value)
+		}
+	}
+
+	return answers, nil
+}
+
+var serviceAnswer = regexp.MustCompile(`^([a-zA-Z0-9][a-zA-Z0-9_.-]*/)?[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+// ValidateAnswers checks the answers against the type and the constraints of
+// their questions, the error lists the missing required answers and then
+// every invalid answer.
+func ValidateAnswers(questions map[string]model.Question, answers map[string]string) error {
+	keys := []string{}
+	for key := range questions {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	missing := []string{}
+	invalid := []string{}
+	for _, key := range keys {
+		question := questions[key]
+		answer := answers[key]
+		if answer == "" {
+			if question.Required {
+				missing = append(missing, key)
+			}
+			continue
+		}
+
+		if err := ValidateAnswer(question, answer); err != nil {
+			invalid = append(invalid, fmt.Sprintf("%s: %v", key, err))
+		}
+	}
+
+	problems := []string{}
+	if len(missing) > 0 {
+		problems = append(problems, fmt.Sprintf("Missing answers for required questions: %s", strings.Join(missing, ", ")))
+	}
+	problems = append(problems, invalid...)
+
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "\n"))
+	}
+	return nil
+}
+
+// ValidateAnswer checks a non empty answer against the type and the
+// constraints of its question. Certificates and services are names of
+// resources of the environment, only their syntax is checked.
+func ValidateAnswer(question model.Question, answer string) error {
+	switch question.Type {
+	case "int":
+		value, err := strconv.Atoi(answer)
+		if err != nil {
+			return fmt.Errorf("%q is not an integer", answer)
+		}
+		if question.Min != 0 && value < question.Min {
+			return fmt.Errorf("%d is less than the minimum %d", value, question.Min)
+		}
+		if question.Max != 0 && value > question.Max {
+			return fmt.Errorf("%d is more than the maximum %d", value, question.Max)
+		}
+		return nil
+	case "boolean":
+		if answer != "true" && answer != "false" {
+			return fmt.Errorf("%q is not true or false", answer)
+		}
+		return nil
+	case "enum":
+		for _, option := range question.Options {
+			if answer == option {
+				return nil
+			}
+		}
+		return fmt.Errorf("%q is not one of %s", answer, strings.Join(question.Options, ", "))
+	case "certificate":
+		if strings.ContainsAny(answer, " \t\r\n") {
+			return fmt.Errorf("%q is not a certificate name", answer)
+		}
+		return nil
+	case "service":
+		if !serviceAnswer.MatchString(answer) {
+			return fmt.Errorf("%q is not a service, expected SERVICE or STACK/SERVICE", answer)
+		}
+		return nil
+	case "", "string", "password", "multiline":
+		return validateText(question, answer)
+	default:
+		return fmt.Errorf("unknown question type %s", question.Type)
+	}
+}
+
+func validateText(question model.Question, answer string) error {
+	length := utf8.RuneCountInString(answer)
+	if question.MinLength != 0 && length < question.MinLength {
+		return fmt.Errorf("must be at least %d characters long", question.MinLength)
+	}
+	if question.MaxLength != 0 && length > question.MaxLength {
+		return fmt.Errorf("must be at most %d characters long", question.MaxLength)
+	}
+
+	for _, c := range answer {
+		if question.ValidChars != "" && !strings.ContainsRune(question.ValidChars, c) {
+			return fmt.Errorf("the character %q is not allowed, expected only %s", c, question.ValidChars)
+		}
+		if question.InvalidChars != "" && strings.ContainsRune(question.InvalidChars, c) {
+			return fmt.Errorf("the character %q is not allowed", c)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package lookup
+
+import (
+	"testing"
+
+	"github.com/rancher/rancher-catalog-service/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateAnswer(t *testing.T) {
+	tests := []struct {
+		question model.Question
+		answer   string
+		valid    bool
+	}{
+		{model.Question{Type: "int"}, "42", true},
+		{model.Question{Type: "int"}, "4.2", false},
+		{model.Question{Type: "int", Min: 1, Max: 10}, "0", false},
+		{model.Question{Type: "int", Min: 1, Max: 10}, "11", false},
+		{model.Question{Type: "boolean"}, "true", true},
+		{model.Question{Type: "boolean"}, "yes", false},
+		{model.Question{Type: "enum", Options: []string{"a", "b"}}, "b", true},
+		{model.Question{Type: "enum", Options: []string{"a", "b"}}, "c", false},
+		{model.Question{Type: "password", MinLength: 8}, "secret", false},
+		{model.Question{Type: "string", MaxLength: 3}, "abcd", false},
+		{model.Question{Type: "string", ValidChars: "abc"}, "cab", true},
+		{model.Question{Type: "string", ValidChars: "abc"}, "cad", false},
+		{model.Question{Type: "string", InvalidChars: "/"}, "a/b", false},
+		{model.Question{Type: "multiline"}, "line one\nline two", true},
+		{model.Question{Type: "certificate"}, "example.com", true},
+		{model.Question{Type: "certificate"}, "not a name", false},
+		{model.Question{Type: "service"}, "stack/web", true},
+		{model.Question{Type: "service"}, "web", true},
+		{model.Question{Type: "service"}, "stack/web/extra", false},
+		{model.Question{Type: "unknown"}, "value", false},
+	}
+
+	for _, test := range tests {
+		err := ValidateAnswer(test.question, test.answer)
+		if test.valid {
+			assert.NoError(t, err, "%s %q", test.question.Type, test.answer)
+		} else {
+			assert.Error(t, err, "%s %q", test.question.Type, test.answer)
+		}
+	}
+}
+
+func TestValidateAnswers(t *testing.T) {
+	err := ValidateAnswers(map[string]model.Question{
+		"PORT":  {Variable: "PORT", Type: "int"},
+		"MODE":  {Variable: "MODE", Type: "enum", Options: []string{"fast", "safe"}},
+		"NAME":  {Variable: "NAME", Required: true},
+		"DEBUG": {Variable: "DEBUG", Type: "boolean"},
+	}, map[string]string{
+		"PORT":  "http",
+		"MODE":  "slow",
+		"DEBUG": "false",
+	})
+
+	assert.EqualError(t, err, `Missing answers for required questions: NAME
+MODE: "slow" is not one of fast, safe
+PORT: "http" is not an integer`)
+}
@@ -5,12 +5,15 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"strconv"
 	"strings"
 
+	"github.com/docker/docker/pkg/term"
 	"github.com/docker/libcompose/utils"
 	"github.com/rancher/rancher-catalog-service/model"
 	"github.com/rancher/rancher-compose-executor/config"
 	rUtils "github.com/rancher/rancher-compose-executor/utils"
+	"golang.org/x/crypto/ssh/terminal"
 	"gopkg.in/yaml.v2"
 )
 
@@ -21,10 +24,15 @@ type questionWrapper struct {
 type QuestionLookup struct {
 	parent    config.EnvironmentLookup
 	questions map[string]model.Question
+	order     []string
 	variables map[string]string
 }
 
-func NewQuestionLookup(file string, parent config.EnvironmentLookup) (*QuestionLookup, error) {
+// NewQuestionLookup answers the questions of the rancher compose file with
+// the given answers first, then with the parent lookup, then on stdin when
+// prompt is set and finally with the default of the question. It fails with
+// every required question that is left without an answer.
+func NewQuestionLookup(file string, answers map[string]string, prompt bool, parent config.EnvironmentLookup) (*QuestionLookup, error) {
 	ret := &QuestionLookup{
 		parent:    parent,
 		variables: map[string]string{},
@@ -35,7 +43,9 @@ func NewQuestionLookup(file string, parent config.EnvironmentLookup) (*QuestionL
 		return nil, err
 	}
 
-	ret.askQuestions()
+	if err := ret.answerQuestions(answers, prompt); err != nil {
+		return nil, err
+	}
 
 	return ret, nil
 }
@@ -48,21 +58,55 @@ func (q *QuestionLookup) parse(file string) error {
 		return err
 	}
 
-	q.questions, err = ParseQuestions(contents)
+	catalogConfig, err := ParseCatalogConfig(contents)
 	if err != nil {
 		return err
 	}
 
+	for _, question := range catalogConfig.Questions {
+		if _, ok := q.questions[question.Variable]; !ok {
+			q.order = append(q.order, question.Variable)
+		}
+		q.questions[question.Variable] = question
+	}
+
 	return nil
 }
 
-func (q *QuestionLookup) askQuestions() {
-	for key, question := range q.questions {
-		answer := ask(question)
+func (q *QuestionLookup) answerQuestions(answers map[string]string, prompt bool) error {
+	for _, key := range q.order {
+		question := q.questions[key]
+
+		answer, ok := answers[key]
+		if !ok {
+			answer = q.parentValue(key)
+		}
+		if answer == "" && prompt {
+			answer = askValid(question)
+		}
+		if answer == "" {
+			answer = question.Default
+		}
+
 		if answer != "" {
 			q.variables[key] = answer
 		}
 	}
+
+	return ValidateAnswers(q.questions, q.variables)
+}
+
+func (q *QuestionLookup) parentValue(key string) string {
+	if q.parent == nil {
+		return ""
+	}
+	for _, value := range q.parent.Lookup(key, nil) {
+		parts := strings.SplitN(value, "=", 2)
+		if len(parts) == 2 && parts[0] == key {
+			return parts[1]
+		}
+	}
+	return ""
 }
 
 func ParseQuestions(contents []byte) (map[string]model.Question, error) {
@@ -125,18 +169,68 @@ func (f *QuestionLookup) Lookup(key string, config *config.ServiceConfig) []stri
 	return f.parent.Lookup(key, config)
 }
 
+// stdin is shared by the prompts so that no buffered input is lost between
+// questions. The prompts go to stderr, stdout is left to the output of the
+// command.
+var stdin = bufio.NewReader(os.Stdin)
+
+// askValid prompts until the answer is valid for the type of the question.
+func askValid(question model.Question) string {
+	for {
+		answer := ask(question)
+		if answer == "" {
+			return answer
+		}
+
+		err := ValidateAnswer(question, answer)
+		if err == nil {
+			return answer
+		}
+		fmt.Fprintf(os.Stderr, "Invalid answer for %s: %v\n", question.Variable, err)
+	}
+}
+
 func ask(question model.Question) string {
 	if len(question.Description) > 0 {
-		fmt.Println(question.Description)
+		fmt.Fprintln(os.Stderr, question.Description)
 	}
-	fmt.Printf("%s %s[%s]: ", question.Label, question.Variable, question.Default)
 
-	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	defaultAnswer := question.Default
+	switch question.Type {
+	case "enum":
+		for i, option := range question.Options {
+			fmt.Fprintf(os.Stderr, "  %d) %s\n", i+1, option)
+		}
+	case "password":
+		if defaultAnswer != "" {
+			defaultAnswer = "********"
+		}
+	case "multiline":
+		fmt.Fprintln(os.Stderr, "End the answer with a line containing only a dot")
+	}
+	fmt.Fprintf(os.Stderr, "%s %s[%s]: ", question.Label, question.Variable, defaultAnswer)
+
+	var answer string
+	var err error
+	switch question.Type {
+	case "password":
+		answer, err = readPassword()
+	case "multiline":
+		answer, err = readMultiline()
+	default:
+		answer, err = stdin.ReadString('\n')
+		answer = strings.TrimSpace(answer)
+	}
 	if err != nil {
 		return ""
 	}
 
-	answer = strings.TrimSpace(answer)
+	if question.Type == "enum" {
+		if i, err := strconv.Atoi(answer); err == nil && i > 0 && i <= len(question.Options) {
+			answer = question.Options[i-1]
+		}
+	}
+
 	if answer == "" {
 		answer = question.Default
 	}
@@ -144,6 +238,41 @@ func ask(question model.Question) string {
 	return answer
 }
 
+// readPassword reads a line without echoing it when stdin is a terminal.
+func readPassword() (string, error) {
+	fd, isTerminal := term.GetFdInfo(os.Stdin)
+	if !isTerminal {
+		answer, err := stdin.ReadString('\n')
+		return strings.TrimSpace(answer), err
+	}
+
+	answer, err := terminal.ReadPassword(int(fd))
+	fmt.Fprintln(os.Stderr)
+	return strings.TrimSpace(string(answer)), err
+}
+
+// readMultiline reads lines until one only holds a dot, the lines are kept
+// as they are typed.
+func readMultiline() (string, error) {
+	lines := []string{}
+	for {
+		line, err := stdin.ReadString('\n')
+		line = strings.TrimRight(line, "\r\n")
+		if line == "." {
+			break
+		}
+		if err != nil {
+			if len(lines) == 0 && line == "" {
+				return "", err
+			}
+			lines = append(lines, line)
+			break
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
 func (f *QuestionLookup) Variables() map[string]string {
 	// TODO: precedence
 	return rUtils.MapUnion(f.variables, f.parent.Variables())
@@ -0,0 +1,92 @@
+package lookup
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testQuestions = `
+.catalog:
+  questions:
+  - variable: FROM_ANSWERS
+    default: default
+  - variable: FROM_ENV
+    default: default
+  - variable: FROM_DEFAULT
+    default: default
+  - variable: REQUIRED_ONE
+    required: true
+  - variable: REQUIRED_TWO
+    required: true
+  - variable: OPTIONAL
+`
+
+func writeTestFile(t *testing.T, name, contents string) string {
+	dir, err := ioutil.TempDir("", "questions")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(file, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestQuestionLookupAnswers(t *testing.T) {
+	file := writeTestFile(t, "rancher-compose.yml", testQuestions)
+	defer os.RemoveAll(filepath.Dir(file))
+
+	env := &MapEnvLookup{Env: map[string]interface{}{
+		"FROM_ANSWERS": "env",
+		"FROM_ENV":     "env",
+		"REQUIRED_TWO": "env",
+	}}
+
+	q, err := NewQuestionLookup(file, map[string]string{
+		"FROM_ANSWERS": "answers",
+		"REQUIRED_ONE": "answers",
+	}, false, env)
+	assert.NoError(t, err)
+
+	assert.Equal(t, map[string]string{
+		"FROM_ANSWERS": "answers",
+		"FROM_ENV":     "env",
+		"FROM_DEFAULT": "default",
+		"REQUIRED_ONE": "answers",
+		"REQUIRED_TWO": "env",
+	}, q.variables)
+	assert.Equal(t, []string{"FROM_ANSWERS=answers"}, q.Lookup("FROM_ANSWERS", nil))
+}
+
+func TestQuestionLookupMissingAnswers(t *testing.T) {
+	file := writeTestFile(t, "rancher-compose.yml", testQuestions)
+	defer os.RemoveAll(filepath.Dir(file))
+
+	_, err := NewQuestionLookup(file, nil, false, &MapEnvLookup{})
+	assert.EqualError(t, err, "Missing answers for required questions: REQUIRED_ONE, REQUIRED_TWO")
+}
+
+func TestParseAnswers(t *testing.T) {
+	file := writeTestFile(t, "answers.json", `{"NAME": "web", "SCALE": 2, "DEBUG": true, "EMPTY": null}`)
+	defer os.RemoveAll(filepath.Dir(file))
+
+	answers, err := ParseAnswers(file)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"NAME":  "web",
+		"SCALE": "2",
+		"DEBUG": "true",
+		"EMPTY": "",
+	}, answers)
+
+	file = writeTestFile(t, "answers.yml", "NAME:\n  nested: value\n")
+	defer os.RemoveAll(filepath.Dir(file))
+
+	_, err = ParseAnswers(file)
+	assert.Error(t, err)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"time"
 
 	"github.com/Sirupsen/logrus"
 	rancherApp "github.com/rancher/rancher-compose-executor/app"
@@ -16,6 +17,14 @@ func beforeApp(c *cli.Context) error {
 	if c.GlobalBool("verbose") {
 		logrus.SetLevel(logrus.DebugLevel)
 	}
+
+	switch c.GlobalString("output") {
+	case "json":
+		logrus.SetFormatter(&logrus.JSONFormatter{})
+	case "text":
+	default:
+		return fmt.Errorf("Invalid output %s, expected text or json", c.GlobalString("output"))
+	}
 	return nil
 }
 
@@ -73,6 +82,37 @@ func cliMain() {
 			),
 			EnvVar: "RANCHER_SECRET_KEY",
 		},
+		cli.StringFlag{
+			Name:   "environment",
+			Usage:  "Specify the name or the ID of the Rancher environment to use, it needs an account API key",
+			EnvVar: "RANCHER_ENVIRONMENT",
+		},
+		cli.StringFlag{
+			Name:   "ca-cert",
+			Usage:  "Specify a PEM file with the CA certificates of the Rancher API",
+			EnvVar: "RANCHER_CA_CERT",
+		},
+		cli.BoolFlag{
+			Name:   "insecure-skip-verify",
+			Usage:  "Do not verify the TLS certificate of the Rancher API",
+			EnvVar: "RANCHER_INSECURE_SKIP_VERIFY",
+		},
+		cli.StringFlag{
+			Name:   "proxy",
+			Usage:  "Specify the HTTP proxy to reach the Rancher API (default: $HTTPS_PROXY or $HTTP_PROXY)",
+			EnvVar: "RANCHER_PROXY",
+		},
+		cli.DurationFlag{
+			Name:   "api-timeout",
+			Usage:  "Specify the timeout of the requests to the Rancher API",
+			Value:  10 * time.Second,
+			EnvVar: "RANCHER_API_TIMEOUT",
+		},
+		cli.StringFlag{
+			Name:   "profile",
+			Usage:  "Specify the profile of ~/.rancher/compose.json with the URL and keys to use",
+			EnvVar: "RANCHER_PROFILE",
+		},
 		cli.StringFlag{
 			Name:  "rancher-file,r",
 			Usage: "Specify an alternate Rancher compose file (default: rancher-compose.yml)",
@@ -85,10 +125,38 @@ func cliMain() {
 			Name:  "bindings-file,b",
 			Usage: "Specify a file from which to read bindings",
 		},
+		cli.StringFlag{
+			Name:  "answers",
+			Usage: "Specify a YAML or JSON file with the answers to the questions of rancher-compose.yml",
+		},
+		cli.BoolFlag{
+			Name:  "no-prompt",
+			Usage: "Do not prompt for the questions, the ones without an answer take their default",
+		},
+		cli.StringFlag{
+			Name:  "output,o",
+			Usage: "Output format (text or json), json prints one record per line and a summary",
+			Value: "text",
+		},
 	}
 	app.Commands = []cli.Command{
 		rancherApp.CreateCommand(factory),
 		rancherApp.UpCommand(factory),
+		rancherApp.BuildCommand(factory),
+		rancherApp.StartCommand(factory),
+		rancherApp.StopCommand(factory),
+		rancherApp.RestartCommand(factory),
+		rancherApp.ScaleCommand(factory),
+		rancherApp.LogsCommand(factory),
+		rancherApp.PsCommand(factory),
+		rancherApp.ExecCommand(factory),
+		rancherApp.RunCommand(factory),
+		rancherApp.PullCommand(factory),
+		rancherApp.PlanCommand(factory),
+		rancherApp.ConfigCommand(factory),
+		rancherApp.ValidateCommand(),
+		rancherApp.RemoveCommand(factory),
+		rancherApp.DownCommand(factory),
 	}
 
 	if err := app.Run(os.Args); err != nil {
@@ -2,9 +2,15 @@ package project
 
 import (
 	"fmt"
+	"sort"
+	"strings"
+	"sync"
 
 	"golang.org/x/net/context"
 
+	log "github.com/Sirupsen/logrus"
+	"github.com/pkg/errors"
+	"github.com/rancher/rancher-compose-executor/config"
 	"github.com/rancher/rancher-compose-executor/project/events"
 	"github.com/rancher/rancher-compose-executor/project/options"
 	"github.com/rancher/rancher-compose-executor/template"
@@ -32,14 +38,259 @@ func (p *Project) Create(ctx context.Context, options options.Create, services .
 	}), nil)
 }
 
-func (p *Project) Log(ctx context.Context, follow bool, services ...string) error {
+func (p *Project) Delete(ctx context.Context, options options.Delete, services ...string) error {
+	err := p.perform(events.ProjectDeleteStart, events.ProjectDeleteDone, services, wrapperAction(func(wrapper *serviceWrapper, wrappers map[string]*serviceWrapper) {
+		wrapper.DoReverse(wrappers, events.ServiceDeleteStart, events.ServiceDelete, func(service Service) error {
+			return service.Delete(ctx, options)
+		})
+	}), nil)
+	if err != nil {
+		return err
+	}
+
+	if options.RemoveVolume {
+		return p.removeVolumes(ctx, services)
+	}
+	return nil
+}
+
+func (p *Project) Down(ctx context.Context, opts options.Down, services ...string) error {
+	err := p.perform(events.ProjectDownStart, events.ProjectDownDone, services, wrapperAction(func(wrapper *serviceWrapper, wrappers map[string]*serviceWrapper) {
+		wrapper.DoReverse(wrappers, events.ServiceDownStart, events.ServiceDown, func(service Service) error {
+			return service.Delete(ctx, options.Delete{
+				RemoveVolume:  opts.RemoveVolume,
+				RemoveRunning: true,
+			})
+		})
+	}), nil)
+	if err != nil {
+		return err
+	}
+
+	if opts.RemoveOrphans && p.context.Runtime != nil {
+		if err := p.context.Runtime.RemoveOrphans(ctx, p.Name, p.ServiceConfigs); err != nil {
+			return err
+		}
+	}
+
+	if opts.RemoveVolume {
+		return p.removeVolumes(ctx, services)
+	}
+	return nil
+}
+
+func (p *Project) removeVolumes(ctx context.Context, services []string) error {
+	if p.volumes == nil {
+		return nil
+	}
+	// Volumes are declared for the whole stack, removing them while some
+	// services are left behind would pull storage from under those services.
+	if len(services) > 0 {
+		log.Warnf("Not removing volumes of project %s since only some services were removed", p.Name)
+		return nil
+	}
+	return p.volumes.Remove(ctx)
+}
+
+// Pull pulls the images of all services even when some fail, the error names
+// every service that failed.
+func (p *Project) Pull(ctx context.Context, services ...string) error {
+	var lock sync.Mutex
+	failed := map[string]error{}
+
+	err := p.forEach(services, wrapperAction(func(wrapper *serviceWrapper, wrappers map[string]*serviceWrapper) {
+		wrapper.Do(nil, events.ServicePullStart, events.ServicePull, func(service Service) error {
+			err := service.Pull(ctx)
+			if err != nil {
+				lock.Lock()
+				failed[service.Name()] = err
+				lock.Unlock()
+			}
+			return err
+		})
+	}), nil)
+	if err != nil && len(failed) == 0 {
+		return err
+	}
+
+	if len(failed) == 0 {
+		return nil
+	}
+
+	names := []string{}
+	for name := range failed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	messages := []string{}
+	for _, name := range names {
+		messages = append(messages, fmt.Sprintf("%s: %v", name, failed[name]))
+	}
+	return fmt.Errorf("Failed to pull %d of the services: %s", len(failed), strings.Join(messages, "; "))
+}
+
+// Plan computes what up would change for the services, the services of the
+// stack that are not defined in the project are listed as orphans when no
+// service is selected.
+func (p *Project) Plan(ctx context.Context, services ...string) (Plan, error) {
+	plan := Plan{Services: []*ServicePlan{}}
+
+	names := services
+	if len(names) == 0 {
+		names = p.ServiceConfigs.Keys()
+		sort.Strings(names)
+	}
+
+	for _, name := range names {
+		service, err := p.CreateService(name)
+		if err != nil {
+			return Plan{}, err
+		}
+
+		servicePlan, err := service.Plan(ctx)
+		if err != nil {
+			return Plan{}, err
+		}
+
+		if servicePlan != nil {
+			plan.Services = append(plan.Services, servicePlan)
+		}
+	}
+
+	if len(services) == 0 && p.context.Runtime != nil {
+		orphans, err := p.context.Runtime.Orphans(ctx, p.Name, p.ServiceConfigs)
+		if err != nil {
+			return Plan{}, err
+		}
+		plan.Orphans = orphans
+	}
+
+	return plan, nil
+}
+
+func (p *Project) Ps(ctx context.Context, services ...string) (InfoSet, error) {
+	allInfo := InfoSet{}
+
+	if len(services) == 0 {
+		services = p.ServiceConfigs.Keys()
+		sort.Strings(services)
+	}
+
+	for _, name := range services {
+		service, err := p.CreateService(name)
+		if err != nil {
+			return nil, err
+		}
+
+		info, err := service.Info(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		allInfo = append(allInfo, info...)
+	}
+
+	return allInfo, nil
+}
+
+// Exec runs a command in a container of the service and returns its exit code.
+func (p *Project) Exec(ctx context.Context, serviceName string, commandParts []string, options options.Exec) (int, error) {
+	if !p.ServiceConfigs.Has(serviceName) {
+		return 1, fmt.Errorf("%s is not defined in the template", serviceName)
+	}
+
+	service, err := p.CreateService(serviceName)
+	if err != nil {
+		return 1, err
+	}
+
+	return service.Exec(ctx, commandParts, options)
+}
+
+// Run runs a one-off container of the service and returns its exit code.
+func (p *Project) Run(ctx context.Context, serviceName string, commandParts []string, options options.Run) (int, error) {
+	if !p.ServiceConfigs.Has(serviceName) {
+		return 1, fmt.Errorf("%s is not defined in the template", serviceName)
+	}
+
+	service, err := p.CreateService(serviceName)
+	if err != nil {
+		return 1, err
+	}
+
+	p.Notify(events.ServiceRunStart, serviceName, nil)
+	exitCode, err := service.Run(ctx, commandParts, options)
+	if err == nil {
+		p.Notify(events.ServiceRun, serviceName, nil)
+	}
+
+	return exitCode, err
+}
+
+func (p *Project) Log(ctx context.Context, options options.Log, services ...string) error {
 	return p.forEach(services, wrapperAction(func(wrapper *serviceWrapper, wrappers map[string]*serviceWrapper) {
 		wrapper.Do(nil, events.NoEvent, events.NoEvent, func(service Service) error {
-			return service.Log(ctx, follow)
+			return service.Log(ctx, options)
+		})
+	}), nil)
+}
+
+func (p *Project) Start(ctx context.Context, services ...string) error {
+	return p.perform(events.ProjectStartStart, events.ProjectStartDone, services, wrapperAction(func(wrapper *serviceWrapper, wrappers map[string]*serviceWrapper) {
+		wrapper.Do(wrappers, events.ServiceStartStart, events.ServiceStart, func(service Service) error {
+			return service.Start(ctx)
+		})
+	}), nil)
+}
+
+func (p *Project) Stop(ctx context.Context, services ...string) error {
+	return p.perform(events.ProjectStopStart, events.ProjectStopDone, services, wrapperAction(func(wrapper *serviceWrapper, wrappers map[string]*serviceWrapper) {
+		wrapper.DoReverse(wrappers, events.ServiceStopStart, events.ServiceStop, func(service Service) error {
+			return service.Stop(ctx)
 		})
 	}), nil)
 }
 
+func (p *Project) Restart(ctx context.Context, services ...string) error {
+	return p.perform(events.ProjectRestartStart, events.ProjectRestartDone, services, wrapperAction(func(wrapper *serviceWrapper, wrappers map[string]*serviceWrapper) {
+		wrapper.Do(wrappers, events.ServiceRestartStart, events.ServiceRestart, func(service Service) error {
+			return service.Restart(ctx)
+		})
+	}), nil)
+}
+
+func (p *Project) Scale(ctx context.Context, servicesScale map[string]int) error {
+	names := make([]string, 0, len(servicesScale))
+	services := make(map[string]Service)
+
+	for name := range servicesScale {
+		if !p.ServiceConfigs.Has(name) {
+			return fmt.Errorf("%s is not defined in the template", name)
+		}
+
+		service, err := p.CreateService(name)
+		if err != nil {
+			return errors.Wrapf(err, "Failed to lookup service: %s", name)
+		}
+
+		names = append(names, name)
+		services[name] = service
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		scale := servicesScale[name]
+		log.Infof("Setting scale %s=%d...", name, scale)
+		if err := services[name].Scale(ctx, scale); err != nil {
+			return errors.Wrapf(err, "Failed to set the scale %s=%d", name, scale)
+		}
+	}
+
+	return nil
+}
+
 func (p *Project) Up(ctx context.Context, options options.Up, services ...string) error {
 	if err := p.initialize(ctx); err != nil {
 		return err
@@ -53,6 +304,20 @@ func (p *Project) Up(ctx context.Context, options options.Up, services ...string
 	})
 }
 
+// Config returns the configuration of the project once all compose files are
+// merged and interpolated.
+func (p *Project) Config() *config.Config {
+	return &config.Config{
+		Services:     p.ServiceConfigs.All(),
+		Containers:   p.ContainerConfigs.All(),
+		Dependencies: p.DependencyConfigs,
+		Volumes:      p.VolumeConfigs,
+		Networks:     p.NetworkConfigs,
+		Secrets:      p.SecretConfigs,
+		Hosts:        p.HostConfigs,
+	}
+}
+
 func (p *Project) Render() ([][]byte, error) {
 	var renderedComposeBytes [][]byte
 	for _, contents := range p.context.ComposeBytes {
@@ -31,6 +31,7 @@ type Context struct {
 	VolumesFactory      VolumesFactory
 	SecretsFactory      SecretsFactory
 	HostsFactory        HostsFactory
+	Runtime             RuntimeProject
 	EnvironmentLookup   config.EnvironmentLookup
 	ResourceLookup      config.ResourceLookup
 	LoggerFactory       logger.Factory
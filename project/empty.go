@@ -20,18 +20,68 @@ func (e *EmptyService) Create(ctx context.Context, options options.Create) error
 	return nil
 }
 
+// Delete implements Service.Delete but does nothing.
+func (e *EmptyService) Delete(ctx context.Context, options options.Delete) error {
+	return nil
+}
+
 // Build implements Service.Build but does nothing.
 func (e *EmptyService) Build(ctx context.Context, buildOptions options.Build) error {
 	return nil
 }
 
+// Info implements Service.Info but does nothing.
+func (e *EmptyService) Info(ctx context.Context) (InfoSet, error) {
+	return InfoSet{}, nil
+}
+
+// Start implements Service.Start but does nothing.
+func (e *EmptyService) Start(ctx context.Context) error {
+	return nil
+}
+
+// Stop implements Service.Stop but does nothing.
+func (e *EmptyService) Stop(ctx context.Context) error {
+	return nil
+}
+
+// Exec implements Service.Exec but does nothing.
+func (e *EmptyService) Exec(ctx context.Context, commandParts []string, options options.Exec) (int, error) {
+	return 0, nil
+}
+
+// Run implements Service.Run but does nothing.
+func (e *EmptyService) Run(ctx context.Context, commandParts []string, options options.Run) (int, error) {
+	return 0, nil
+}
+
+// Plan implements Service.Plan but does nothing.
+func (e *EmptyService) Plan(ctx context.Context) (*ServicePlan, error) {
+	return nil, nil
+}
+
+// Pull implements Service.Pull but does nothing.
+func (e *EmptyService) Pull(ctx context.Context) error {
+	return nil
+}
+
+// Restart implements Service.Restart but does nothing.
+func (e *EmptyService) Restart(ctx context.Context) error {
+	return nil
+}
+
+// Scale implements Service.Scale but does nothing.
+func (e *EmptyService) Scale(ctx context.Context, count int) error {
+	return nil
+}
+
 // Up implements Service.Up but does nothing.
 func (e *EmptyService) Up(ctx context.Context, options options.Up) error {
 	return nil
 }
 
 // Log implements Service.Log but does nothing.
-func (e *EmptyService) Log(ctx context.Context, follow bool) error {
+func (e *EmptyService) Log(ctx context.Context, options options.Log) error {
 	return nil
 }
 
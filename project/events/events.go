@@ -71,6 +71,13 @@ const (
 	ServiceStop         = EventType(iota)
 	ServiceRunStart     = EventType(iota)
 	ServiceRun          = EventType(iota)
+	ServiceFailed       = EventType(iota)
+	ServiceWaiting      = EventType(iota)
+
+	ServiceUpgradeStart     = EventType(iota)
+	ServiceUpgrade          = EventType(iota)
+	ServiceHealthCheckStart = EventType(iota)
+	ServiceHealthCheck      = EventType(iota)
 
 	VolumeAdd  = EventType(iota)
 	NetworkAdd = EventType(iota)
@@ -101,6 +108,80 @@ const (
 	ProjectStopDone      = EventType(iota)
 )
 
+var eventNames = map[EventType]string{
+	ContainerCreated:        "ContainerCreated",
+	ContainerStarted:        "ContainerStarted",
+	ServiceAdd:              "ServiceAdd",
+	ServiceUpStart:          "ServiceUpStart",
+	ServiceUpIgnored:        "ServiceUpIgnored",
+	ServiceUp:               "ServiceUp",
+	ServiceCreateStart:      "ServiceCreateStart",
+	ServiceCreate:           "ServiceCreate",
+	ServiceDeleteStart:      "ServiceDeleteStart",
+	ServiceDelete:           "ServiceDelete",
+	ServiceDownStart:        "ServiceDownStart",
+	ServiceDown:             "ServiceDown",
+	ServiceRestartStart:     "ServiceRestartStart",
+	ServiceRestart:          "ServiceRestart",
+	ServicePullStart:        "ServicePullStart",
+	ServicePull:             "ServicePull",
+	ServiceKillStart:        "ServiceKillStart",
+	ServiceKill:             "ServiceKill",
+	ServiceStartStart:       "ServiceStartStart",
+	ServiceStart:            "ServiceStart",
+	ServiceBuildStart:       "ServiceBuildStart",
+	ServiceBuild:            "ServiceBuild",
+	ServicePauseStart:       "ServicePauseStart",
+	ServicePause:            "ServicePause",
+	ServiceUnpauseStart:     "ServiceUnpauseStart",
+	ServiceUnpause:          "ServiceUnpause",
+	ServiceStopStart:        "ServiceStopStart",
+	ServiceStop:             "ServiceStop",
+	ServiceRunStart:         "ServiceRunStart",
+	ServiceRun:              "ServiceRun",
+	ServiceFailed:           "ServiceFailed",
+	ServiceWaiting:          "ServiceWaiting",
+	ServiceUpgradeStart:     "ServiceUpgradeStart",
+	ServiceUpgrade:          "ServiceUpgrade",
+	ServiceHealthCheckStart: "ServiceHealthCheckStart",
+	ServiceHealthCheck:      "ServiceHealthCheck",
+	VolumeAdd:               "VolumeAdd",
+	NetworkAdd:              "NetworkAdd",
+	ProjectDownStart:        "ProjectDownStart",
+	ProjectDownDone:         "ProjectDownDone",
+	ProjectCreateStart:      "ProjectCreateStart",
+	ProjectCreateDone:       "ProjectCreateDone",
+	ProjectUpStart:          "ProjectUpStart",
+	ProjectUpDone:           "ProjectUpDone",
+	ProjectDeleteStart:      "ProjectDeleteStart",
+	ProjectDeleteDone:       "ProjectDeleteDone",
+	ProjectRestartStart:     "ProjectRestartStart",
+	ProjectRestartDone:      "ProjectRestartDone",
+	ProjectReload:           "ProjectReload",
+	ProjectReloadTrigger:    "ProjectReloadTrigger",
+	ProjectKillStart:        "ProjectKillStart",
+	ProjectKillDone:         "ProjectKillDone",
+	ProjectStartStart:       "ProjectStartStart",
+	ProjectStartDone:        "ProjectStartDone",
+	ProjectBuildStart:       "ProjectBuildStart",
+	ProjectBuildDone:        "ProjectBuildDone",
+	ProjectPauseStart:       "ProjectPauseStart",
+	ProjectPauseDone:        "ProjectPauseDone",
+	ProjectUnpauseStart:     "ProjectUnpauseStart",
+	ProjectUnpauseDone:      "ProjectUnpauseDone",
+	ProjectStopStart:        "ProjectStopStart",
+	ProjectStopDone:         "ProjectStopDone",
+}
+
+// Name returns the name of the constant of the event type, it does not change
+// between releases unlike the message returned by String.
+func (e EventType) Name() string {
+	if name, ok := eventNames[e]; ok {
+		return name
+	}
+	return fmt.Sprintf("EventType%d", int(e))
+}
+
 func (e EventType) String() string {
 	var m string
 	switch e {
@@ -157,6 +238,18 @@ func (e EventType) String() string {
 		m = "Executing"
 	case ServiceRun:
 		m = "Executed"
+	case ServiceFailed:
+		m = "Failed"
+	case ServiceWaiting:
+		m = "Waiting for dependencies"
+	case ServiceUpgradeStart:
+		m = "Upgrading"
+	case ServiceUpgrade:
+		m = "Upgraded"
+	case ServiceHealthCheckStart:
+		m = "Waiting for health check"
+	case ServiceHealthCheck:
+		m = "Healthy"
 	case ServicePauseStart:
 		m = "Pausing"
 	case ServicePause:
@@ -0,0 +1,35 @@
+package project
+
+import (
+	"bytes"
+	"strings"
+	"text/tabwriter"
+)
+
+// InfoSet holds a list of Info.
+type InfoSet []Info
+
+// Info holds the columns describing a service or one of its containers.
+type Info map[string]string
+
+// String renders the specified columns of the set as a table, with an
+// optional title line.
+func (infos InfoSet) String(columns []string, titleFlag bool) string {
+	buffer := bytes.NewBuffer(nil)
+	writer := tabwriter.NewWriter(buffer, 4, 4, 2, ' ', 0)
+
+	if titleFlag {
+		writer.Write([]byte(strings.Join(columns, "\t") + "\n"))
+	}
+
+	for _, info := range infos {
+		values := make([]string, 0, len(columns))
+		for _, column := range columns {
+			values = append(values, info[column])
+		}
+		writer.Write([]byte(strings.Join(values, "\t") + "\n"))
+	}
+
+	writer.Flush()
+	return buffer.String()
+}
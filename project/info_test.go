@@ -0,0 +1,21 @@
+package project
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInfoSetString(t *testing.T) {
+	infos := InfoSet{
+		{"Name": "web", "State": "active"},
+		{"Name": "stack-web-1", "State": "running", "Host": "host1"},
+	}
+
+	assert.Equal(t, `Name         State    Host
+web          active   
+stack-web-1  running  host1
+`, infos.String([]string{"Name", "State", "Host"}, true))
+
+	assert.Equal(t, "web\nstack-web-1\n", infos.String([]string{"Name"}, false))
+}
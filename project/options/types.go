@@ -1,5 +1,10 @@
 package options
 
+import (
+	"io"
+	"time"
+)
+
 // Build holds options of compose build.
 type Build struct {
 	NoCache     bool
@@ -28,9 +33,52 @@ type Create struct {
 	ForceBuild    bool
 }
 
+// Log holds options of compose logs. A negative Tail leaves the number of
+// lines to the host.
+type Log struct {
+	Follow     bool
+	Tail       int
+	Since      time.Time
+	Timestamps bool
+}
+
+// Exec holds options of compose exec.
+type Exec struct {
+	// Index selects the instance of the service, starting at 1
+	Index  int
+	Tty    bool
+	Width  int
+	Height int
+	// Resize gets the size of the terminal every time it changes
+	Resize <-chan TerminalSize
+	// NoShell runs the command as is, without the shell that reports its
+	// exit code, exec returns ExitCodeUnknown then
+	NoShell bool
+	// BufferStdin reads Stdin to its end before the command starts, so that
+	// the command sees the end of its input
+	BufferStdin bool
+	Stdin       io.Reader
+	Stdout      io.Writer
+}
+
+// ExitCodeUnknown is returned by exec for a command whose exit code can not
+// be known.
+const ExitCodeUnknown = -1
+
+// TerminalSize is the size of a terminal in characters.
+type TerminalSize struct {
+	Width  int
+	Height int
+}
+
 // Run holds options of compose run.
 type Run struct {
 	Detached bool
+	Remove   bool
+	// ServicePorts keeps the ports of the service, which are left out
+	// otherwise
+	ServicePorts bool
+	Stdout       io.Writer
 }
 
 // Up holds options of compose up.
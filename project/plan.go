@@ -0,0 +1,68 @@
+package project
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// PlanAction is what up would do to a service.
+type PlanAction string
+
+// Plan actions
+const (
+	PlanCreate    = PlanAction("create")
+	PlanUpgrade   = PlanAction("upgrade")
+	PlanUnchanged = PlanAction("unchanged")
+
+	// PlanNeedsUpgrade is a service out of sync that up leaves alone
+	// without --upgrade
+	PlanNeedsUpgrade = PlanAction("needs --upgrade")
+)
+
+// ServicePlan describes what up would do to a service and why.
+type ServicePlan struct {
+	Service string     `json:"service"`
+	Action  PlanAction `json:"action"`
+	Reasons []string   `json:"reasons,omitempty"`
+	Diff    []string   `json:"diff,omitempty"`
+}
+
+// Plan holds the plan of every service of a project. Orphans are the
+// services of the stack that are not defined in the compose files, up leaves
+// them alone so they are not changes.
+type Plan struct {
+	Services []*ServicePlan `json:"services"`
+	Orphans  []string       `json:"orphans,omitempty"`
+}
+
+// String renders the plan with one line per service, followed by its reasons
+// and field level changes, and then the orphans.
+func (p Plan) String() string {
+	buffer := bytes.NewBuffer(nil)
+	for _, servicePlan := range p.Services {
+		fmt.Fprintf(buffer, "%s: %s\n", servicePlan.Service, servicePlan.Action)
+		for _, reason := range servicePlan.Reasons {
+			fmt.Fprintf(buffer, "    %s\n", reason)
+		}
+		for _, line := range servicePlan.Diff {
+			fmt.Fprintf(buffer, "        %s\n", line)
+		}
+	}
+	if len(p.Orphans) > 0 {
+		fmt.Fprintf(buffer, "Not defined in the compose files, left alone by up (down --remove-orphans removes them):\n")
+		for _, orphan := range p.Orphans {
+			fmt.Fprintf(buffer, "    %s\n", orphan)
+		}
+	}
+	return buffer.String()
+}
+
+// HasChanges returns whether up would change anything.
+func (p Plan) HasChanges() bool {
+	for _, servicePlan := range p.Services {
+		if servicePlan.Action != PlanUnchanged && servicePlan.Action != PlanNeedsUpgrade {
+			return true
+		}
+	}
+	return false
+}
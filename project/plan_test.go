@@ -0,0 +1,28 @@
+package project
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanOrphans(t *testing.T) {
+	plan := Plan{
+		Services: []*ServicePlan{
+			{Service: "web", Action: PlanUnchanged},
+			{Service: "db", Action: PlanNeedsUpgrade, Reasons: []string{"Image changed"}},
+		},
+		Orphans: []string{"cache"},
+	}
+
+	assert.False(t, plan.HasChanges())
+	assert.Equal(t, `web: unchanged
+db: needs --upgrade
+    Image changed
+Not defined in the compose files, left alone by up (down --remove-orphans removes them):
+    cache
+`, plan.String())
+
+	plan.Services[0].Action = PlanUpgrade
+	assert.True(t, plan.HasChanges())
+}
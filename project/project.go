@@ -3,6 +3,7 @@ package project
 import (
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 
 	"golang.org/x/net/context"
@@ -30,7 +31,10 @@ type Project struct {
 	SecretConfigs     map[string]*config.SecretConfig
 	HostConfigs       map[string]*config.HostConfig
 	Files             []string
-	ReloadCallback    func() error
+	// ServiceFiles lists the files defining each service or container, in
+	// the order they were loaded
+	ServiceFiles   map[string][]string
+	ReloadCallback func() error
 
 	dependencies Dependencies
 	volumes      Volumes
@@ -53,6 +57,7 @@ func NewProject(context *Context) *Project {
 		NetworkConfigs:    make(map[string]*config.NetworkConfig),
 		SecretConfigs:     make(map[string]*config.SecretConfig),
 		HostConfigs:       make(map[string]*config.HostConfig),
+		ServiceFiles:      make(map[string][]string),
 	}
 
 	if context.LoggerFactory == nil {
@@ -170,10 +175,12 @@ func (p *Project) load(file string, bytes []byte) error {
 
 	for name, config := range config.Services {
 		p.ServiceConfigs.Add(name, config)
+		p.ServiceFiles[name] = append(p.ServiceFiles[name], file)
 		p.reload = append(p.reload, name)
 	}
 	for name, config := range config.Containers {
 		p.ContainerConfigs.Add(name, config)
+		p.ServiceFiles[name] = append(p.ServiceFiles[name], file)
 		p.reload = append(p.reload, name)
 	}
 
@@ -285,7 +292,7 @@ func (p *Project) forEach(services []string, action wrapperAction, cycleAction s
 	return p.traverse(true, selected, wrappers, action, cycleAction)
 }
 
-func (p *Project) startService(wrappers map[string]*serviceWrapper, history []string, selected, launched map[string]bool, wrapper *serviceWrapper, action wrapperAction, cycleAction serviceAction) error {
+func (p *Project) startService(wrappers map[string]*serviceWrapper, history []string, selected, launched map[string]bool, pending *[]*serviceWrapper, wrapper *serviceWrapper, cycleAction serviceAction) error {
 	if launched[wrapper.name] {
 		return nil
 	}
@@ -320,15 +327,14 @@ func (p *Project) startService(wrappers map[string]*serviceWrapper, history []st
 			continue
 		}
 
-		err := p.startService(wrappers, history, selected, launched, target, action, cycleAction)
+		err := p.startService(wrappers, history, selected, launched, pending, target, cycleAction)
 		if err != nil {
 			return err
 		}
 	}
 
 	if isSelected(wrapper, selected) {
-		log.Debugf("Launching action for %s", wrapper.name)
-		go action(wrapper, wrappers)
+		*pending = append(*pending, wrapper)
 	} else {
 		wrapper.Ignore()
 	}
@@ -336,6 +342,56 @@ func (p *Project) startService(wrappers map[string]*serviceWrapper, history []st
 	return nil
 }
 
+// Cycles walks the dependencies of every service the way startService does
+// and returns the paths of the cycles that can not be ignored. Dependencies on
+// services that are not defined are skipped.
+func (p *Project) Cycles() ([]string, error) {
+	services := map[string]Service{}
+	for _, name := range p.ServiceConfigs.Keys() {
+		service, err := p.CreateService(name)
+		if err != nil {
+			return nil, err
+		}
+		services[name] = service
+	}
+
+	cycles := []string{}
+	launched := map[string]bool{}
+
+	var visit func(name string, history []string)
+	visit = func(name string, history []string) {
+		if launched[name] {
+			return
+		}
+
+		launched[name] = true
+		history = append(history, name)
+
+		for _, dep := range services[name].DependentServices() {
+			if _, ok := services[dep.Target]; !ok {
+				continue
+			}
+
+			if utils.Contains(history, dep.Target) {
+				if !dep.Optional {
+					cycles = append(cycles, strings.Join(append(history, dep.Target), "->"))
+				}
+				continue
+			}
+
+			visit(dep.Target, history)
+		}
+	}
+
+	names := p.ServiceConfigs.Keys()
+	sort.Strings(names)
+	for _, name := range names {
+		visit(name, nil)
+	}
+
+	return cycles, nil
+}
+
 func (p *Project) traverse(start bool, selected map[string]bool, wrappers map[string]*serviceWrapper, action wrapperAction, cycleAction serviceAction) error {
 	restart := false
 	wrapperList := []string{}
@@ -367,13 +423,21 @@ func (p *Project) traverse(start bool, selected map[string]bool, wrappers map[st
 	}
 
 	launched := map[string]bool{}
+	pending := []*serviceWrapper{}
 
 	for _, wrapper := range wrappers {
-		if err := p.startService(wrappers, []string{}, selected, launched, wrapper, action, cycleAction); err != nil {
+		if err := p.startService(wrappers, []string{}, selected, launched, &pending, wrapper, cycleAction); err != nil {
 			return err
 		}
 	}
 
+	// Actions are only launched once the whole graph has been walked so that
+	// every ignored dependency cycle is known before any wrapper starts waiting.
+	for _, wrapper := range pending {
+		log.Debugf("Launching action for %s", wrapper.name)
+		go action(wrapper, wrappers)
+	}
+
 	var firstError error
 
 	for _, wrapper := range wrappers {
@@ -415,6 +479,17 @@ func (p *Project) AddListener(c chan<- events.Event) {
 	}
 }
 
+// RemoveListener removes the specified listener from the project, it is not
+// notified anymore and can stop reading. The listener is not closed.
+func (p *Project) RemoveListener(c chan<- events.Event) {
+	for i, l := range p.listeners {
+		if l == c {
+			p.listeners = append(p.listeners[:i], p.listeners[i+1:]...)
+			return
+		}
+	}
+}
+
 // Notify notifies all project listener with the specified eventType, service name and datas.
 // This implements implicitly events.Notifier interface.
 func (p *Project) Notify(eventType events.EventType, serviceName string, data map[string]string) {
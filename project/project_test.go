@@ -0,0 +1,167 @@
+package project
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/rancher/rancher-compose-executor/config"
+	"github.com/rancher/rancher-compose-executor/project/events"
+	"github.com/rancher/rancher-compose-executor/project/options"
+	"github.com/stretchr/testify/assert"
+)
+
+type recorder struct {
+	sync.Mutex
+	calls []string
+}
+
+func (r *recorder) record(call string) {
+	r.Lock()
+	defer r.Unlock()
+	r.calls = append(r.calls, call)
+}
+
+type testService struct {
+	EmptyService
+	name     string
+	config   *config.ServiceConfig
+	project  *Project
+	recorder *recorder
+}
+
+func (s *testService) Name() string {
+	return s.name
+}
+
+func (s *testService) Config() *config.ServiceConfig {
+	return s.config
+}
+
+func (s *testService) DependentServices() []ServiceRelationship {
+	return DefaultDependentServices(s.project, s)
+}
+
+func (s *testService) Create(ctx context.Context, options options.Create) error {
+	s.recorder.record("create:" + s.name)
+	return nil
+}
+
+func (s *testService) Delete(ctx context.Context, options options.Delete) error {
+	s.recorder.record("delete:" + s.name)
+	return nil
+}
+
+func (s *testService) Pull(ctx context.Context) error {
+	s.recorder.record("pull:" + s.name)
+	if s.config.Image == "" {
+		return fmt.Errorf("No image for %s", s.name)
+	}
+	return nil
+}
+
+type testVolumes struct {
+	recorder *recorder
+}
+
+func (v *testVolumes) Initialize(ctx context.Context) error {
+	return nil
+}
+
+func (v *testVolumes) Remove(ctx context.Context) error {
+	v.recorder.record("remove:volumes")
+	return nil
+}
+
+type testServiceFactory struct {
+	recorder *recorder
+}
+
+func (f *testServiceFactory) Create(p *Project, name string, serviceConfig *config.ServiceConfig) (Service, error) {
+	return &testService{
+		name:     name,
+		config:   serviceConfig,
+		project:  p,
+		recorder: f.recorder,
+	}, nil
+}
+
+func newTestProject(r *recorder) *Project {
+	p := NewProject(&Context{
+		ServiceFactory: &testServiceFactory{recorder: r},
+	})
+	p.ServiceConfigs.Add("web", &config.ServiceConfig{Links: []string{"app"}})
+	p.ServiceConfigs.Add("app", &config.ServiceConfig{Links: []string{"db"}})
+	p.ServiceConfigs.Add("db", &config.ServiceConfig{})
+	return p
+}
+
+func TestCreateOrder(t *testing.T) {
+	r := &recorder{}
+	err := newTestProject(r).Create(context.Background(), options.Create{})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"create:db", "create:app", "create:web"}, r.calls)
+}
+
+func TestDeleteOrder(t *testing.T) {
+	r := &recorder{}
+	err := newTestProject(r).Delete(context.Background(), options.Delete{})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"delete:web", "delete:app", "delete:db"}, r.calls)
+}
+
+func TestDeleteSelected(t *testing.T) {
+	r := &recorder{}
+	err := newTestProject(r).Delete(context.Background(), options.Delete{}, "db")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"delete:db"}, r.calls)
+}
+
+func TestDownOrder(t *testing.T) {
+	r := &recorder{}
+	p := newTestProject(r)
+	p.volumes = &testVolumes{recorder: r}
+
+	err := p.Down(context.Background(), options.Down{RemoveVolume: true})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"delete:web", "delete:app", "delete:db", "remove:volumes"}, r.calls)
+
+	r.calls = nil
+	err = p.Down(context.Background(), options.Down{RemoveVolume: true}, "web")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"delete:web"}, r.calls)
+}
+
+func TestPullAllErrors(t *testing.T) {
+	r := &recorder{}
+	p := newTestProject(r)
+	web, _ := p.ServiceConfigs.Get("web")
+	web.Image = "nginx"
+
+	err := p.Pull(context.Background())
+	assert.EqualError(t, err, "Failed to pull 2 of the services: app: No image for app; db: No image for db")
+	assert.Len(t, r.calls, 3)
+}
+
+func TestPullUnknownService(t *testing.T) {
+	r := &recorder{}
+	err := newTestProject(r).Pull(context.Background(), "cache")
+	assert.Error(t, err)
+	assert.Empty(t, r.calls)
+}
+
+func TestRemoveListener(t *testing.T) {
+	p := newTestProject(&recorder{})
+	kept := make(chan events.Event, 1)
+	removed := make(chan events.Event, 1)
+	p.AddListener(kept)
+	p.AddListener(removed)
+
+	p.RemoveListener(removed)
+	p.Notify(events.ServiceUp, "web", nil)
+
+	assert.Len(t, kept, 1)
+	assert.Len(t, removed, 0)
+}
@@ -0,0 +1,12 @@
+package project
+
+import (
+	"github.com/rancher/rancher-compose-executor/config"
+	"golang.org/x/net/context"
+)
+
+// RuntimeProject defines runtime-specific methods for a project.
+type RuntimeProject interface {
+	Orphans(ctx context.Context, projectName string, serviceConfigs *config.ServiceConfigs) ([]string, error)
+	RemoveOrphans(ctx context.Context, projectName string, serviceConfigs *config.ServiceConfigs) error
+}
@@ -64,12 +64,17 @@ func (s *serviceWrapper) waitForDeps(wrappers map[string]*serviceWrapper) bool {
 		return true
 	}
 
+	waiting := false
 	for _, dep := range s.service.DependentServices() {
 		if s.ignored[dep.Target] {
 			continue
 		}
 
 		if wrapper, ok := wrappers[dep.Target]; ok {
+			if !waiting {
+				s.project.Notify(events.ServiceWaiting, s.service.Name(), nil)
+				waiting = true
+			}
 			if wrapper.Wait() == ErrRestart {
 				s.project.Notify(events.ProjectReload, wrapper.service.Name(), nil)
 				s.err = ErrRestart
@@ -83,14 +88,58 @@ func (s *serviceWrapper) waitForDeps(wrappers map[string]*serviceWrapper) bool {
 	return true
 }
 
+// waitForDependents blocks until every service depending on this one is done,
+// which is the order needed to tear a project down.
+func (s *serviceWrapper) waitForDependents(wrappers map[string]*serviceWrapper) bool {
+	if s.noWait {
+		return true
+	}
+
+	waiting := false
+	for _, wrapper := range wrappers {
+		if wrapper == s || wrapper.ignored[s.name] {
+			continue
+		}
+
+		for _, dep := range wrapper.service.DependentServices() {
+			if dep.Target != s.name {
+				continue
+			}
+
+			if !waiting {
+				s.project.Notify(events.ServiceWaiting, s.service.Name(), nil)
+				waiting = true
+			}
+			if wrapper.Wait() == ErrRestart {
+				s.project.Notify(events.ProjectReload, wrapper.service.Name(), nil)
+				s.err = ErrRestart
+				return false
+			}
+			break
+		}
+	}
+
+	return true
+}
+
 func (s *serviceWrapper) Do(wrappers map[string]*serviceWrapper, start, done events.EventType, action func(service Service) error) {
+	s.do(wrappers, s.waitForDeps, start, done, action)
+}
+
+// DoReverse is like Do but runs the action only after the services that
+// depend on this one are done.
+func (s *serviceWrapper) DoReverse(wrappers map[string]*serviceWrapper, start, done events.EventType, action func(service Service) error) {
+	s.do(wrappers, s.waitForDependents, start, done, action)
+}
+
+func (s *serviceWrapper) do(wrappers map[string]*serviceWrapper, wait func(map[string]*serviceWrapper) bool, start, done events.EventType, action func(service Service) error) {
 	defer s.done.Done()
 
 	if s.state == StateExecuted {
 		return
 	}
 
-	if wrappers != nil && !s.waitForDeps(wrappers) {
+	if wrappers != nil && !wait(wrappers) {
 		return
 	}
 
@@ -104,6 +153,9 @@ func (s *serviceWrapper) Do(wrappers map[string]*serviceWrapper, start, done eve
 		s.project.Notify(events.ProjectReloadTrigger, s.service.Name(), nil)
 	} else if s.err != nil {
 		log.Errorf("Failed %s %s : %v", start, s.name, s.err)
+		s.project.Notify(events.ServiceFailed, s.service.Name(), map[string]string{
+			"error": s.err.Error(),
+		})
 	} else {
 		s.project.Notify(done, s.service.Name(), nil)
 	}
@@ -13,7 +13,17 @@ import (
 type Service interface {
 	Build(ctx context.Context, buildOptions options.Build) error
 	Create(ctx context.Context, options options.Create) error
-	Log(ctx context.Context, follow bool) error
+	Delete(ctx context.Context, options options.Delete) error
+	Exec(ctx context.Context, commandParts []string, options options.Exec) (int, error)
+	Info(ctx context.Context) (InfoSet, error)
+	Log(ctx context.Context, options options.Log) error
+	Plan(ctx context.Context) (*ServicePlan, error)
+	Pull(ctx context.Context) error
+	Restart(ctx context.Context) error
+	Run(ctx context.Context, commandParts []string, options options.Run) (int, error)
+	Scale(ctx context.Context, count int) error
+	Start(ctx context.Context) error
+	Stop(ctx context.Context) error
 	Up(ctx context.Context, options options.Up) error
 
 	DependentServices() []ServiceRelationship
@@ -8,6 +8,7 @@ import (
 
 type Volumes interface {
 	Initialize(ctx context.Context) error
+	Remove(ctx context.Context) error
 }
 
 type VolumesFactory interface {
@@ -1,6 +1,7 @@
 package rancher
 
 import (
+	"bufio"
 	"crypto/sha256"
 	"encoding/hex"
 	"errors"
@@ -12,12 +13,17 @@ import (
 	"path/filepath"
 	"strings"
 
+	"golang.org/x/net/context"
+
 	"github.com/Sirupsen/logrus"
 	"github.com/docker/docker/builder"
 	"github.com/docker/docker/builder/dockerignore"
 	"github.com/docker/docker/pkg/archive"
 	"github.com/docker/docker/pkg/fileutils"
+	"github.com/rancher/rancher-compose-executor/config"
 	"github.com/rancher/rancher-compose-executor/project"
+	"github.com/rancher/rancher-compose-executor/project/events"
+	"github.com/rancher/rancher-compose-executor/project/options"
 )
 
 const DefaultDockerfileName = "Dockerfile"
@@ -27,19 +33,149 @@ type Uploader interface {
 	Name() string
 }
 
+// CachedUploader is implemented by uploaders that can find a build context
+// that was uploaded before, the hash being the one of the archive.
+type CachedUploader interface {
+	Uploaded(p *project.Project, name string, hash string) (string, string, bool, error)
+}
+
 func Upload(c *Context, name string) (string, string, error) {
+	return upload(c, name, false, c.ReadOnly)
+}
+
+// build uploads the build context of the service and sends the image and the
+// URL of the upload with a build event. The hosts build the image from that
+// URL when the service is deployed, contexts that are already remote have
+// nothing to upload. A context is uploaded again only when its archive
+// changed or NoCache is set, and with Pull the hosts pull the base images
+// first so that they build on the latest ones. Read only mode does not apply
+// since uploading is what build is for.
+func (r *RancherService) build(ctx context.Context, buildOptions options.Build) error {
+	serviceConfig := r.serviceConfig
+	if serviceConfig.Build.Context == "" {
+		return nil
+	}
+
+	if config.IsValidRemote(serviceConfig.Build.Context) {
+		logrus.Infof("Build for %s uses the remote context %s, nothing to upload", r.name, serviceConfig.Build.Context)
+		return nil
+	}
+
+	if buildOptions.Pull {
+		if err := r.pullBaseImages(ctx); err != nil {
+			return err
+		}
+	}
+
+	image, url, err := upload(r.context, r.name, !buildOptions.NoCache, false)
+	if err != nil {
+		return err
+	}
+
+	r.context.Project.Notify(events.ServiceBuild, r.name, map[string]string{
+		"image": image,
+		"url":   url,
+	})
+	return nil
+}
+
+// pullBaseImages pulls the images the Dockerfile of the service builds from
+// on the hosts.
+func (r *RancherService) pullBaseImages(ctx context.Context) error {
+	dockerfile := r.serviceConfig.Build.Dockerfile
+	if dockerfile == "" {
+		dockerfile = DefaultDockerfileName
+	}
+
+	file, err := os.Open(filepath.Join(r.serviceConfig.Build.Context, dockerfile))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	images, err := baseImages(file)
+	if err != nil {
+		return err
+	}
+
+	for _, image := range images {
+		logrus.Infof("Pulling %s, the base image of %s", image, r.name)
+		if err := r.pullImage(ctx, image, r.serviceConfig.Labels); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// baseImages returns the images named by the FROM instructions of a
+// Dockerfile, leaving out scratch, earlier stages and the images named with
+// build arguments, which are only known to the hosts.
+func baseImages(dockerfile io.Reader) ([]string, error) {
+	images := []string{}
+	stages := map[string]bool{"scratch": true}
+
+	scanner := bufio.NewScanner(dockerfile)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || !strings.EqualFold(fields[0], "FROM") {
+			continue
+		}
+
+		args := fields[1:]
+		for len(args) > 1 && strings.HasPrefix(args[0], "--") {
+			args = args[1:]
+		}
+		image := args[0]
+
+		switch {
+		case stages[strings.ToLower(image)]:
+		case strings.Contains(image, "$"):
+			logrus.Warnf("Not pulling %s, it depends on build arguments", image)
+		default:
+			images = append(images, image)
+		}
+
+		if len(args) >= 3 && strings.EqualFold(args[1], "AS") {
+			stages[strings.ToLower(args[2])] = true
+		}
+	}
+	return images, scanner.Err()
+}
+
+// upload creates the build archive of a service and uploads it, an earlier
+// upload of the same archive is reused when cached is set and the uploader
+// supports it. Nothing is uploaded in dry run, only the image is named.
+func upload(c *Context, name string, cached, dryRun bool) (string, string, error) {
 	uploader := c.Uploader
 	if uploader == nil {
 		return "", "", errors.New("Build not supported")
 	}
 	p := c.Project
-	logrus.Infof("Uploading build for %s using provider %s", name, uploader.Name())
-
 	content, hash, err := createBuildArchive(p, name)
 	if err != nil {
 		return "", "", err
 	}
+	if closer, ok := content.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	if dryRun {
+		logrus.Infof("Not uploading build for %s in read only mode", name)
+		return fmt.Sprintf("%s-%s", name, hash[:12]), "", nil
+	}
 
+	if cachedUploader, ok := uploader.(CachedUploader); ok && cached {
+		image, url, found, err := cachedUploader.Uploaded(p, name, hash)
+		if err != nil {
+			return "", "", err
+		}
+		if found {
+			logrus.Infof("Build for %s was already uploaded", name)
+			return image, url, nil
+		}
+	}
+
+	logrus.Infof("Uploading build for %s using provider %s", name, uploader.Name())
 	return uploader.Upload(p, name, content, hash)
 }
 
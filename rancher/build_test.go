@@ -0,0 +1,22 @@
+package rancher
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBaseImages(t *testing.T) {
+	images, err := baseImages(strings.NewReader(`ARG VERSION=1.8
+FROM golang:1.8 AS builder
+RUN go build
+from --platform=linux/amd64 alpine:3.5
+FROM builder
+FROM scratch
+FROM node:$VERSION
+COPY --from=builder /go/bin/app /app
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"golang:1.8", "alpine:3.5"}, images)
+}
@@ -37,11 +37,53 @@ func NewContainer(name string, config *config.ServiceConfig, context *Context) *
 	}
 }
 
+// notSupported is returned by the commands that can not act on the entries
+// under containers: yet, so that they do not report success for nothing.
+func (r *RancherContainer) notSupported(command string) error {
+	return fmt.Errorf("%s is not supported for containers: %s", command, r.name)
+}
+
 func (r *RancherContainer) Create(ctx context.Context, options options.Create) error {
 	fmt.Println(r.Name(), "Create")
 	return nil
 }
 
+func (r *RancherContainer) Delete(ctx context.Context, options options.Delete) error {
+	return r.notSupported("rm")
+}
+
+func (r *RancherContainer) Exec(ctx context.Context, commandParts []string, options options.Exec) (int, error) {
+	return 1, r.notSupported("exec")
+}
+
+func (r *RancherContainer) Run(ctx context.Context, commandParts []string, options options.Run) (int, error) {
+	return 1, r.notSupported("run")
+}
+
+func (r *RancherContainer) Info(ctx context.Context) (project.InfoSet, error) {
+	return nil, r.notSupported("ps")
+}
+
+func (r *RancherContainer) Plan(ctx context.Context) (*project.ServicePlan, error) {
+	return nil, r.notSupported("plan")
+}
+
+func (r *RancherContainer) Start(ctx context.Context) error {
+	return r.notSupported("start")
+}
+
+func (r *RancherContainer) Stop(ctx context.Context) error {
+	return r.notSupported("stop")
+}
+
+func (r *RancherContainer) Restart(ctx context.Context) error {
+	return r.notSupported("restart")
+}
+
+func (r *RancherContainer) Scale(ctx context.Context, count int) error {
+	return r.notSupported("scale")
+}
+
 func (r *RancherContainer) Up(ctx context.Context, options options.Up) error {
 	fmt.Println(r.Name(), "Up")
 	return nil
@@ -52,7 +94,7 @@ func (r *RancherContainer) Build(ctx context.Context, buildOptions options.Build
 	return nil
 }
 
-func (r *RancherContainer) Log(ctx context.Context, follow bool) error {
+func (r *RancherContainer) Log(ctx context.Context, options options.Log) error {
 	fmt.Println(r.Name(), "Log")
 	return nil
 }
@@ -1,9 +1,12 @@
 package rancher
 
 import (
+	"crypto/tls"
 	"fmt"
 	"regexp"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/rancher/go-rancher/v2"
@@ -20,6 +23,7 @@ type Context struct {
 	AccessKey    string
 	SecretKey    string
 	Client       *client.RancherClient
+	Environment  string
 	Stack        *client.Stack
 	isOpen       bool
 	SidekickInfo *SidekickInfo
@@ -28,12 +32,48 @@ type Context struct {
 	Pull         bool
 	Args         []string
 
+	CACert             string
+	InsecureSkipVerify bool
+	Proxy              string
+	APITimeout         time.Duration
+	tls                *tls.Config
+
 	Upgrade        bool
 	ForceUpgrade   bool
 	Rollback       bool
 	Interval       int64
 	BatchSize      int64
 	ConfirmUpgrade bool
+
+	// ReadOnly makes sure nothing is changed on the server, a missing stack is
+	// not created and builds are only uploaded by the build command
+	ReadOnly bool
+
+	// ExistingStack fails with an error when the stack does not exist
+	// instead of creating it
+	ExistingStack bool
+
+	// PullConcurrency bounds the number of images pulled at once, 0 means no limit
+	PullConcurrency int
+	pullSlotsOnce   sync.Once
+	pullSlots       chan struct{}
+}
+
+func (c *Context) acquirePullSlot() {
+	c.pullSlotsOnce.Do(func() {
+		if c.PullConcurrency > 0 {
+			c.pullSlots = make(chan struct{}, c.PullConcurrency)
+		}
+	})
+	if c.pullSlots != nil {
+		c.pullSlots <- struct{}{}
+	}
+}
+
+func (c *Context) releasePullSlot() {
+	if c.pullSlots != nil {
+		<-c.pullSlots
+	}
 }
 
 func (c *Context) sanitizedProjectName() string {
@@ -52,11 +92,29 @@ func (c *Context) loadClient() (*client.RancherClient, error) {
 			return nil, fmt.Errorf("RANCHER_URL is not set")
 		}
 
-		if client, err := client.NewRancherClient(&client.ClientOpts{
+		transport, err := c.transport()
+		if err != nil {
+			return nil, err
+		}
+
+		opts := client.ClientOpts{
 			Url:       c.Url,
 			AccessKey: c.AccessKey,
 			SecretKey: c.SecretKey,
-		}); err != nil {
+			Timeout:   c.apiTimeout(),
+			Transport: transport,
+		}
+
+		if c.Environment != "" {
+			url, err := environmentURL(opts, c.Environment)
+			if err != nil {
+				return nil, err
+			}
+			logrus.Debugf("Using environment %s at %s", c.Environment, url)
+			opts.Url = url
+		}
+
+		if client, err := client.NewRancherClient(&opts); err != nil {
 			return nil, err
 		} else {
 			c.Client = client
@@ -78,6 +136,9 @@ func (c *Context) open() error {
 	}
 
 	if stackSchema, ok := c.Client.GetTypes()["stack"]; !ok || !utils.Contains(stackSchema.CollectionMethods, "POST") {
+		if c.Environment != "" {
+			return fmt.Errorf("API key [%s] can not create stacks in environment %s", c.AccessKey, c.Environment)
+		}
 		return fmt.Errorf("Can not create a stack, check API key [%s] for [%s]", c.AccessKey, c.Url)
 	}
 
@@ -137,6 +198,18 @@ func (c *Context) LoadStack() (*client.Stack, error) {
 		}
 	}
 
+	if c.ExistingStack {
+		return nil, fmt.Errorf("Stack %s does not exist", projectName)
+	}
+
+	if c.ReadOnly {
+		logrus.Debugf("Stack %s does not exist", projectName)
+		c.Stack = &client.Stack{
+			Name: projectName,
+		}
+		return c.Stack, nil
+	}
+
 	logrus.Infof("Creating stack %s", projectName)
 	stack, err := c.Client.Stack.Create(&client.Stack{
 		Name: projectName,
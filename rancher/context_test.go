@@ -0,0 +1,52 @@
+package rancher
+
+import (
+	"testing"
+
+	"github.com/rancher/go-rancher/v2"
+	"github.com/rancher/rancher-compose-executor/project"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeStacks struct {
+	client.StackOperations
+	created []*client.Stack
+}
+
+func (f *fakeStacks) List(opts *client.ListOpts) (*client.StackCollection, error) {
+	return &client.StackCollection{}, nil
+}
+
+func (f *fakeStacks) Create(stack *client.Stack) (*client.Stack, error) {
+	f.created = append(f.created, stack)
+	return stack, nil
+}
+
+func TestLoadMissingStack(t *testing.T) {
+	for _, test := range []struct {
+		readOnly, existingStack bool
+		created                 int
+		err                     string
+	}{
+		{created: 1},
+		{readOnly: true},
+		{existingStack: true, err: "Stack web does not exist"},
+	} {
+		stacks := &fakeStacks{}
+		c := &Context{
+			Context:       project.Context{ProjectName: "web"},
+			Client:        &client.RancherClient{Stack: stacks},
+			ReadOnly:      test.readOnly,
+			ExistingStack: test.existingStack,
+		}
+
+		stack, err := c.LoadStack()
+		if test.err != "" {
+			assert.EqualError(t, err, test.err)
+			assert.Nil(t, stack)
+		} else if assert.NoError(t, err) {
+			assert.Equal(t, "web", stack.Name)
+		}
+		assert.Len(t, stacks.created, test.created)
+	}
+}
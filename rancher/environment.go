@@ -0,0 +1,79 @@
+package rancher
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/rancher/go-rancher/v2"
+)
+
+var environmentPathRegexp = regexp.MustCompile(`/projects/[^/]+$`)
+
+// accountURL strips the environment and the schemas from the URL of the API.
+func accountURL(url string) string {
+	url = strings.TrimSuffix(url, "/")
+	url = strings.TrimSuffix(url, "/schemas")
+	return environmentPathRegexp.ReplaceAllString(url, "")
+}
+
+// environmentURL finds an environment by ID or by name through the account
+// API and returns the URL of its API, built the same way as the executor
+// builds it from the account of a stack.
+func environmentURL(opts client.ClientOpts, environment string) (string, error) {
+	opts.Url = accountURL(opts.Url)
+	accountClient, err := client.NewRancherClient(&opts)
+	if err != nil {
+		return "", err
+	}
+
+	if _, ok := accountClient.GetTypes()["project"]; !ok {
+		return "", fmt.Errorf("API key [%s] can not list environments, an account API key is needed to select environment %s", opts.AccessKey, environment)
+	}
+
+	project, err := findEnvironment(accountClient, environment)
+	if err != nil {
+		return "", err
+	}
+	if project == nil {
+		return "", fmt.Errorf("Environment %s does not exist or API key [%s] can not access it", environment, opts.AccessKey)
+	}
+
+	return fmt.Sprintf("%s/projects/%s/schemas", opts.Url, project.Id), nil
+}
+
+func findEnvironment(c *client.RancherClient, environment string) (*client.Project, error) {
+	projects, err := c.Project.List(&client.ListOpts{
+		Filters: map[string]interface{}{
+			"name":         environment,
+			"removed_null": "1",
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	switch len(projects.Data) {
+	case 1:
+		return &projects.Data[0], nil
+	case 0:
+	default:
+		ids := []string{}
+		for _, project := range projects.Data {
+			ids = append(ids, project.Id)
+		}
+		return nil, fmt.Errorf("Several environments are named %s, select one of them by ID: %s", environment, strings.Join(ids, ", "))
+	}
+
+	project, err := c.Project.ById(environment)
+	if _, ok := err.(*client.ApiError); ok {
+		// The API refuses IDs of environments the key has no access to
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if project == nil || project.Removed != "" {
+		return nil, nil
+	}
+	return project, nil
+}
@@ -0,0 +1,20 @@
+package rancher
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccountURL(t *testing.T) {
+	for url, expected := range map[string]string{
+		"http://localhost:8080":                               "http://localhost:8080",
+		"http://localhost:8080/v2-beta/":                      "http://localhost:8080/v2-beta",
+		"http://localhost:8080/v2-beta/schemas":               "http://localhost:8080/v2-beta",
+		"http://localhost:8080/v2-beta/projects/1a5":          "http://localhost:8080/v2-beta",
+		"http://localhost:8080/v2-beta/projects/1a5/schemas":  "http://localhost:8080/v2-beta",
+		"http://localhost:8080/v2-beta/projects/1a5/schemas/": "http://localhost:8080/v2-beta",
+	} {
+		assert.Equal(t, expected, accountURL(url), url)
+	}
+}
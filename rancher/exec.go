@@ -0,0 +1,381 @@
+package rancher
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strconv"
+
+	"golang.org/x/net/context"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/gorilla/websocket"
+	"github.com/pkg/errors"
+	"github.com/rancher/go-rancher/v2"
+	"github.com/rancher/rancher-compose-executor/project/options"
+)
+
+// exitCodeUnknown is options.ExitCodeUnknown, which the options arguments hide.
+const exitCodeUnknown = options.ExitCodeUnknown
+
+func (r *RancherService) Exec(ctx context.Context, commandParts []string, options options.Exec) (int, error) {
+	return r.execLaunchConfig(ctx, primaryLaunchConfig, commandParts, options)
+}
+
+// execLaunchConfig runs a command in the running container at options.Index
+// among the containers of the given launch config, ordered by creation.
+func (r *RancherService) execLaunchConfig(ctx context.Context, launchConfig string, commandParts []string, options options.Exec) (int, error) {
+	service, err := r.FindExisting(r.name)
+	if err != nil {
+		return 1, err
+	}
+	if service == nil {
+		return 1, fmt.Errorf("Service %s does not exist", r.name)
+	}
+
+	containers, err := r.containers()
+	if err != nil {
+		return 1, err
+	}
+
+	running := []client.Container{}
+	for _, container := range containers {
+		if container.State == "running" && isLaunchConfig(&container, launchConfig) {
+			running = append(running, container)
+		}
+	}
+	sort.Sort(byCreateIndex(running))
+
+	if options.Index < 1 || options.Index > len(running) {
+		name := r.name
+		if launchConfig != primaryLaunchConfig {
+			name = launchConfig
+		}
+		return 1, fmt.Errorf("Service %s has no running container with index %d", name, options.Index)
+	}
+
+	return r.exec(ctx, &running[options.Index-1], commandParts, options)
+}
+
+// exec runs the command through the execute action of the host API. The
+// websocket only carries base64 encoded stdin and stdout, so the command is
+// wrapped in a shell that prints the exit code after a marker, which is
+// stripped from the output. With a terminal the shell also sizes it and
+// prints its name, so that every resize can run stty on it from a second
+// session.
+func (r *RancherService) exec(ctx context.Context, container *client.Container, commandParts []string, options options.Exec) (int, error) {
+	token, err := newExecToken()
+	if err != nil {
+		return 1, err
+	}
+
+	// The websocket can not be half closed, the end of the input is passed
+	// by giving the command exactly the bytes that were read.
+	var stdin []byte
+	streamStdin := options.Stdin != nil && (!options.BufferStdin || options.NoShell)
+	if options.Stdin != nil && !streamStdin {
+		if stdin, err = ioutil.ReadAll(options.Stdin); err != nil {
+			return 1, err
+		}
+	}
+
+	command := commandParts
+	if !options.NoShell {
+		command = wrapExecCommand(commandParts, token, len(stdin), options)
+	}
+
+	logrus.Debugf("Executing %v in %s", commandParts, container.Name)
+	conn, err := r.context.hostAccess(container.Resource, "execute", &client.ContainerExec{
+		AttachStdin:  streamStdin || len(stdin) > 0,
+		AttachStdout: true,
+		Tty:          options.Tty,
+		Command:      command,
+	})
+	if err != nil {
+		return 1, errors.Wrapf(err, "Failed to exec in %s", container.Name)
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+		}
+		conn.Close()
+	}()
+
+	if streamStdin {
+		go forwardStdin(conn, options.Stdin)
+	} else if len(stdin) > 0 {
+		go forwardStdin(conn, bytes.NewReader(stdin))
+	}
+
+	stdout := options.Stdout
+	if stdout == nil {
+		stdout = ioutil.Discard
+	}
+
+	var output io.Writer = stdout
+	exitCodes := newExitCodeWriter(stdout, exitMarker(token))
+	if !options.NoShell {
+		output = exitCodes
+		if options.Tty {
+			ttyNames := newTTYNameWriter(exitCodes, ttyMarker(token))
+			go r.forwardResize(container, ttyNames.name, options.Resize, done)
+			output = ttyNames
+		}
+	}
+
+	for {
+		messageType, message, err := conn.ReadMessage()
+		if err != nil {
+			break
+		}
+		if messageType != websocket.TextMessage {
+			continue
+		}
+
+		data, err := base64.StdEncoding.DecodeString(string(message))
+		if err != nil {
+			data = message
+		}
+		if _, err := output.Write(data); err != nil {
+			return 1, err
+		}
+	}
+
+	// Without the shell nothing reports the exit code
+	if options.NoShell {
+		return exitCodeUnknown, nil
+	}
+
+	exitCode, ok := exitCodes.exitCode()
+	if !ok {
+		return 1, fmt.Errorf("Lost connection to %s before the command finished, images without /bin/sh need --no-shell", container.Name)
+	}
+	return exitCode, nil
+}
+
+// forwardResize sizes the terminal of an exec session from a second session
+// running stty, once the name of the terminal is known. Only the last size
+// is applied when several changes come in while stty runs.
+func (r *RancherService) forwardResize(container *client.Container, name <-chan string, resize <-chan options.TerminalSize, done <-chan struct{}) {
+	if resize == nil {
+		return
+	}
+
+	var tty string
+	select {
+	case tty = <-name:
+	case <-done:
+		return
+	}
+
+	for {
+		select {
+		case size := <-resize:
+			r.resizeTTY(container, tty, size)
+		case <-done:
+			return
+		}
+	}
+}
+
+func (r *RancherService) resizeTTY(container *client.Container, tty string, size options.TerminalSize) {
+	conn, err := r.context.hostAccess(container.Resource, "execute", &client.ContainerExec{
+		AttachStdout: true,
+		Command:      []string{"stty", "-F", tty, "cols", strconv.Itoa(size.Width), "rows", strconv.Itoa(size.Height)},
+	})
+	if err != nil {
+		logrus.Debugf("Failed to resize %s in %s: %v", tty, container.Name, err)
+		return
+	}
+	defer conn.Close()
+
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
+
+func forwardStdin(conn *websocket.Conn, stdin io.Reader) {
+	buffer := make([]byte, 4096)
+	for {
+		n, err := stdin.Read(buffer)
+		if n > 0 {
+			message := base64.StdEncoding.EncodeToString(buffer[:n])
+			if err := conn.WriteMessage(websocket.TextMessage, []byte(message)); err != nil {
+				return
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func newExecToken() (string, error) {
+	token := make([]byte, 8)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(token), nil
+}
+
+func exitMarker(token string) string {
+	return "rancher-compose-exit-" + token + ":"
+}
+
+func ttyMarker(token string) string {
+	return "rancher-compose-tty-" + token + ":"
+}
+
+// wrapExecCommand runs the command in a shell that prints the exit code after
+// the exit marker. With a terminal the shell first prints the name of the
+// terminal between the tty marker and a colon. Buffered input of the given
+// length is passed through head, which ends the input of the command.
+func wrapExecCommand(commandParts []string, token string, stdinLength int, options options.Exec) []string {
+	script := ""
+	if options.Tty {
+		if options.Width > 0 && options.Height > 0 {
+			script = fmt.Sprintf("stty cols %d rows %d 2>/dev/null; ", options.Width, options.Height)
+		}
+		script += fmt.Sprintf(`printf '%s%%s:' "$(tty)"; `, ttyMarker(token))
+	}
+	if stdinLength > 0 {
+		script += fmt.Sprintf("head -c %d | ", stdinLength)
+	}
+	script += fmt.Sprintf(`"$@"; printf '%s%%d' $?`, exitMarker(token))
+
+	return append([]string{"/bin/sh", "-c", script, "sh"}, commandParts...)
+}
+
+// ttyNameWriter strips the name of the terminal that the shell prints first
+// from the output and passes it on, the output is passed through as is when
+// it does not start with the marker.
+type ttyNameWriter struct {
+	out    io.Writer
+	marker []byte
+	head   []byte
+	done   bool
+	name   chan string
+}
+
+func newTTYNameWriter(out io.Writer, marker string) *ttyNameWriter {
+	return &ttyNameWriter{
+		out:    out,
+		marker: []byte(marker),
+		name:   make(chan string, 1),
+	}
+}
+
+func (w *ttyNameWriter) Write(p []byte) (int, error) {
+	if w.done {
+		return w.out.Write(p)
+	}
+
+	w.head = append(w.head, p...)
+	if n := len(w.marker); len(w.head) < n {
+		if bytes.HasPrefix(w.marker, w.head) {
+			return len(p), nil
+		}
+	} else if bytes.HasPrefix(w.head, w.marker) {
+		end := bytes.IndexByte(w.head[n:], ':')
+		if end < 0 {
+			return len(p), nil
+		}
+		w.name <- string(w.head[n : n+end])
+		w.head = w.head[n+end+1:]
+	}
+
+	w.done = true
+	if _, err := w.out.Write(w.head); err != nil {
+		return 0, err
+	}
+	w.head = nil
+	return len(p), nil
+}
+
+// exitCodeWriter passes the output of an exec session through as it comes,
+// only holding back an end that may be the start of the exit marker printed
+// at the very end.
+type exitCodeWriter struct {
+	out    io.Writer
+	marker []byte
+	tail   []byte
+	found  bool
+}
+
+func newExitCodeWriter(out io.Writer, marker string) *exitCodeWriter {
+	return &exitCodeWriter{
+		out:    out,
+		marker: []byte(marker),
+	}
+}
+
+func (w *exitCodeWriter) Write(p []byte) (int, error) {
+	w.tail = append(w.tail, p...)
+	if w.found {
+		return len(p), nil
+	}
+
+	if i := bytes.Index(w.tail, w.marker); i >= 0 {
+		w.found = true
+		_, err := w.out.Write(w.tail[:i])
+		w.tail = w.tail[i:]
+		return len(p), err
+	}
+
+	if n := len(w.tail) - markerStart(w.tail, w.marker); n > 0 {
+		if _, err := w.out.Write(w.tail[:n]); err != nil {
+			return 0, err
+		}
+		w.tail = append(w.tail[:0], w.tail[n:]...)
+	}
+
+	return len(p), nil
+}
+
+// markerStart returns the length of the longest end of p that the marker
+// starts with.
+func markerStart(p, marker []byte) int {
+	n := len(marker) - 1
+	if n > len(p) {
+		n = len(p)
+	}
+	for ; n > 0; n-- {
+		if bytes.HasSuffix(p, marker[:n]) {
+			return n
+		}
+	}
+	return 0
+}
+
+// exitCode flushes the output and returns the exit code that followed the
+// marker, ok is false when the marker was never seen.
+func (w *exitCodeWriter) exitCode() (int, bool) {
+	if !w.found {
+		w.out.Write(w.tail)
+		return 0, false
+	}
+
+	code := bytes.TrimSpace(w.tail[len(w.marker):])
+	exitCode, err := strconv.Atoi(string(code))
+	if err != nil {
+		return 0, false
+	}
+	return exitCode, true
+}
+
+type byCreateIndex []client.Container
+
+func (c byCreateIndex) Len() int           { return len(c) }
+func (c byCreateIndex) Swap(i, j int)      { c[i], c[j] = c[j], c[i] }
+func (c byCreateIndex) Less(i, j int) bool { return c[i].CreateIndex < c[j].CreateIndex }
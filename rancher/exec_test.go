@@ -0,0 +1,76 @@
+package rancher
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/rancher/rancher-compose-executor/project/options"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExitCodeWriter(t *testing.T) {
+	marker := "rancher-compose-exit-0123:"
+	output := &bytes.Buffer{}
+	w := newExitCodeWriter(output, marker)
+
+	for _, chunk := range []string{"hello\n", "world\nrancher-com", "pose-exit-0123", ":42"} {
+		w.Write([]byte(chunk))
+	}
+
+	exitCode, ok := w.exitCode()
+	assert.True(t, ok)
+	assert.Equal(t, 42, exitCode)
+	assert.Equal(t, "hello\nworld\n", output.String())
+}
+
+func TestExitCodeWriterFlush(t *testing.T) {
+	output := &bytes.Buffer{}
+	w := newExitCodeWriter(output, "rancher-compose-exit-0123:")
+
+	w.Write([]byte("/ # "))
+	assert.Equal(t, "/ # ", output.String())
+	w.Write([]byte("ls\r\nbin  etc\r\n/ # rancher"))
+	assert.Equal(t, "/ # ls\r\nbin  etc\r\n/ # ", output.String())
+	w.Write([]byte("s"))
+	assert.Equal(t, "/ # ls\r\nbin  etc\r\n/ # ranchers", output.String())
+}
+
+func TestExitCodeWriterWithoutMarker(t *testing.T) {
+	output := &bytes.Buffer{}
+	w := newExitCodeWriter(output, "rancher-compose-exit-0123:")
+	w.Write([]byte("partial"))
+
+	_, ok := w.exitCode()
+	assert.False(t, ok)
+	assert.Equal(t, "partial", output.String())
+}
+
+func TestTTYNameWriter(t *testing.T) {
+	output := &bytes.Buffer{}
+	w := newTTYNameWriter(output, "rancher-compose-tty-0123:")
+
+	for _, chunk := range []string{"rancher-compose", "-tty-0123:/dev/", "pts/3:hello", "\n"} {
+		w.Write([]byte(chunk))
+	}
+
+	assert.Equal(t, "/dev/pts/3", <-w.name)
+	assert.Equal(t, "hello\n", output.String())
+
+	output.Reset()
+	w = newTTYNameWriter(output, "rancher-compose-tty-0123:")
+	w.Write([]byte("ran"))
+	w.Write([]byte("dom output"))
+	assert.Equal(t, "random output", output.String())
+	assert.Len(t, w.name, 0)
+}
+
+func TestWrapExecCommand(t *testing.T) {
+	assert.Equal(t, []string{"/bin/sh", "-c", `"$@"; printf 'rancher-compose-exit-0123:%d' $?`, "sh", "ls", "-l"},
+		wrapExecCommand([]string{"ls", "-l"}, "0123", 0, options.Exec{}))
+
+	assert.Equal(t, []string{"/bin/sh", "-c", `head -c 12 | "$@"; printf 'rancher-compose-exit-0123:%d' $?`, "sh", "cat"},
+		wrapExecCommand([]string{"cat"}, "0123", 12, options.Exec{}))
+
+	assert.Equal(t, []string{"/bin/sh", "-c", `stty cols 80 rows 24 2>/dev/null; printf 'rancher-compose-tty-0123:%s:' "$(tty)"; "$@"; printf 'rancher-compose-exit-0123:%d' $?`, "sh", "bash"},
+		wrapExecCommand([]string{"bash"}, "0123", 0, options.Exec{Tty: true, Width: 80, Height: 24}))
+}
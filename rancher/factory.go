@@ -1,12 +1,17 @@
 package rancher
 
-import "github.com/rancher/rancher-compose-executor/digest"
+import (
+	"golang.org/x/net/context"
+
+	"github.com/rancher/rancher-compose-executor/digest"
+)
 
 type Factory interface {
 	Hash(service *RancherService) (digest.ServiceHash, error)
+	Config(service *RancherService) (digest.ServiceHash, *CompositeService, error)
 	Create(service *RancherService) error
-	Upgrade(r *RancherService, force bool, selected []string) error
-	Rollback(r *RancherService) error
+	Upgrade(ctx context.Context, r *RancherService, force bool, selected []string) error
+	Rollback(ctx context.Context, r *RancherService) error
 }
 
 func GetFactory(service *RancherService) (Factory, error) {
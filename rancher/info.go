@@ -0,0 +1,135 @@
+package rancher
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/context"
+
+	"github.com/rancher/rancher-compose-executor/digest"
+	"github.com/rancher/rancher-compose-executor/project"
+)
+
+const (
+	inSync    = "in-sync"
+	outOfSync = "out-of-sync"
+)
+
+func (r *RancherService) Info(ctx context.Context) (project.InfoSet, error) {
+	return r.info(primaryLaunchConfig)
+}
+
+// info describes the service, when launchConfig is the primary one, followed
+// by the containers started from launchConfig.
+func (r *RancherService) info(launchConfig string) (project.InfoSet, error) {
+	result := project.InfoSet{}
+
+	service, err := r.FindExisting(r.name)
+	if err != nil {
+		return nil, err
+	}
+
+	if service == nil {
+		if launchConfig == primaryLaunchConfig {
+			result = append(result, project.Info{
+				"Name":    r.name,
+				"Service": r.name,
+				"Type":    "service",
+				"State":   "not created",
+			})
+		}
+		return result, nil
+	}
+
+	factory, err := GetFactory(r)
+	if err != nil {
+		return nil, err
+	}
+
+	hash, err := factory.Hash(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if launchConfig == primaryLaunchConfig {
+		existingHash, _ := digest.LookupHash(service)
+		result = append(result, project.Info{
+			"Name":    r.name,
+			"Service": r.name,
+			"Type":    "service",
+			"State":   service.State,
+			"Health":  service.HealthState,
+			"Scale":   fmt.Sprintf("%d/%d", service.CurrentScale, service.Scale),
+			"Sync":    syncStatus(existingHash.Equals(hash)),
+		})
+	}
+
+	containers, err := r.containers()
+	if err != nil {
+		return nil, err
+	}
+
+	expectedHash := hash.LaunchConfig
+	if launchConfig != primaryLaunchConfig {
+		expectedHash = hash.SecondaryLaunchConfigs[launchConfig]
+	}
+
+	hosts := map[string]string{}
+	for _, container := range containers {
+		if !isLaunchConfig(&container, launchConfig) {
+			continue
+		}
+
+		host, err := r.hostName(hosts, container.HostId)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, project.Info{
+			"Name":    container.Name,
+			"Service": r.name,
+			"Type":    "container",
+			"State":   container.State,
+			"Health":  container.HealthState,
+			"Host":    host,
+			"Ports":   strings.Join(container.Ports, ","),
+			"Sync":    syncStatus(container.Labels[digest.ServiceHashKey] == expectedHash),
+		})
+	}
+
+	return result, nil
+}
+
+func (r *RancherService) hostName(cache map[string]string, hostId string) (string, error) {
+	if hostId == "" {
+		return "", nil
+	}
+
+	if name, ok := cache[hostId]; ok {
+		return name, nil
+	}
+
+	host, err := r.context.Client.Host.ById(hostId)
+	if err != nil {
+		return "", err
+	}
+
+	name := hostId
+	if host != nil {
+		if host.Name != "" {
+			name = host.Name
+		} else if host.Hostname != "" {
+			name = host.Hostname
+		}
+	}
+
+	cache[hostId] = name
+	return name, nil
+}
+
+func syncStatus(inSyncWithConfig bool) string {
+	if inSyncWithConfig {
+		return inSync
+	}
+	return outOfSync
+}
@@ -0,0 +1,206 @@
+package rancher
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/libcompose/logger"
+	"github.com/gorilla/websocket"
+	"github.com/rancher/go-rancher/v2"
+	"github.com/rancher/rancher-compose-executor/project/options"
+)
+
+var (
+	// How often the instances of a service are listed again while following
+	// logs, so containers added by a scale or an upgrade are picked up.
+	logsRefreshInterval = 5 * time.Second
+	logsReconnectDelay  = time.Second
+)
+
+func (r *RancherService) Log(ctx context.Context, options options.Log) error {
+	return r.logLaunchConfig(ctx, primaryLaunchConfig, options)
+}
+
+// logLaunchConfig streams the logs of the containers of this service that
+// were started from the given launch config, that is either the primary one or
+// one of the sidekicks. When following, the instances are listed periodically
+// until the context is done.
+func (r *RancherService) logLaunchConfig(ctx context.Context, launchConfig string, options options.Log) error {
+	service, err := r.FindExisting(r.name)
+	if err != nil || service == nil {
+		return err
+	}
+
+	if service.Type != "service" {
+		return nil
+	}
+
+	wg := sync.WaitGroup{}
+	streaming := map[string]bool{}
+
+	for {
+		containers, err := r.containers()
+		if err != nil {
+			logrus.Errorf("Failed to list containers to log: %v", err)
+			return err
+		}
+
+		for _, container := range containers {
+			if streaming[container.Id] || !isLaunchConfig(&container, launchConfig) {
+				continue
+			}
+			streaming[container.Id] = true
+
+			wg.Add(1)
+			go func(container client.Container) {
+				defer wg.Done()
+				r.streamLogs(ctx, &container, options)
+			}(container)
+		}
+
+		if !options.Follow {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return nil
+		case <-time.After(logsRefreshInterval):
+		}
+	}
+
+	wg.Wait()
+	return nil
+}
+
+// streamLogs pipes the logs of a container to its logger. When following, the
+// websocket is opened again after it drops for as long as the container
+// exists, skipping the lines that were already printed.
+func (r *RancherService) streamLogs(ctx context.Context, container *client.Container, options options.Log) {
+	logName := strings.TrimPrefix(container.Name, r.context.ProjectName+"_")
+	logger := r.context.LoggerFactory.CreateContainerLogger(logName)
+	var last time.Time
+	for first := true; ; first = false {
+		if first || container.State == "running" {
+			conn, err := r.context.hostAccess(container.Resource, "logs", logsInput(options))
+			if err != nil {
+				logrus.Errorf("Failed to get logs for %s: %v", container.Name, err)
+			} else {
+				last = r.pipeLogs(ctx, logger, conn, last, options)
+			}
+		}
+
+		if !options.Follow {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(logsReconnectDelay):
+		}
+
+		if err := r.context.Client.Reload(&container.Resource, container); err != nil {
+			logrus.Debugf("Stopped following logs for %s: %v", container.Name, err)
+			return
+		}
+
+		switch container.State {
+		case "removing", "removed", "purging", "purged":
+			return
+		}
+
+		logrus.Debugf("Reconnecting to logs of %s", container.Name)
+	}
+}
+
+// logsInput builds the input of the logs action. The host API only knows
+// follow and lines, lines is left out for a negative tail and sent as is
+// otherwise, 0 included.
+func logsInput(options options.Log) map[string]interface{} {
+	input := map[string]interface{}{
+		"follow": options.Follow,
+	}
+	if options.Tail >= 0 {
+		input["lines"] = options.Tail
+	}
+	return input
+}
+
+// pipeLogs copies the messages of a logs websocket to the logger and returns
+// the timestamp of the last message it printed.
+func (r *RancherService) pipeLogs(ctx context.Context, logger logger.Logger, conn *websocket.Conn, last time.Time, options options.Log) time.Time {
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+			conn.Close()
+		}
+	}()
+
+	for {
+		messageType, bytes, err := conn.ReadMessage()
+
+		if err == io.EOF {
+			return last
+		} else if err != nil {
+			if !options.Follow {
+				logrus.Errorf("Failed to read log: %v", err)
+			}
+			return last
+		}
+
+		if messageType != websocket.TextMessage || len(bytes) <= 3 {
+			continue
+		}
+
+		if bytes[len(bytes)-1] != '\n' {
+			bytes = append(bytes, '\n')
+		}
+
+		timestamp, message := splitTimestamp(bytes[3:])
+		if !timestamp.IsZero() {
+			if !timestamp.After(last) || timestamp.Before(options.Since) {
+				continue
+			}
+			last = timestamp
+			if !options.Timestamps {
+				bytes = append(bytes[:3], message...)
+			}
+		}
+
+		if "01" == string(bytes[:2]) {
+			logger.Out(bytes[3:])
+		} else {
+			logger.Err(bytes[3:])
+		}
+	}
+}
+
+// splitTimestamp separates the RFC3339 timestamp the host API puts in front
+// of every log line from the message, there is no parameter to ask for it.
+// The timestamp is zero when the line does not start with one, such lines are
+// neither filtered by --since nor stripped without --timestamps.
+func splitTimestamp(line []byte) (time.Time, []byte) {
+	i := bytes.IndexByte(line, ' ')
+	if i < 0 {
+		return time.Time{}, line
+	}
+
+	timestamp, err := time.Parse(time.RFC3339Nano, string(line[:i]))
+	if err != nil {
+		return time.Time{}, line
+	}
+
+	return timestamp, line[i+1:]
+}
@@ -0,0 +1,50 @@
+package rancher
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rancher/go-rancher/v2"
+	"github.com/rancher/rancher-compose-executor/project/options"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitTimestamp(t *testing.T) {
+	timestamp, message := splitTimestamp([]byte("2017-03-01T10:00:00.123456789Z hello world\n"))
+	assert.Equal(t, time.Date(2017, 3, 1, 10, 0, 0, 123456789, time.UTC), timestamp)
+	assert.Equal(t, "hello world\n", string(message))
+
+	timestamp, message = splitTimestamp([]byte("hello world\n"))
+	assert.True(t, timestamp.IsZero())
+	assert.Equal(t, "hello world\n", string(message))
+
+	timestamp, message = splitTimestamp([]byte("hello\n"))
+	assert.True(t, timestamp.IsZero())
+	assert.Equal(t, "hello\n", string(message))
+}
+
+func TestIsLaunchConfig(t *testing.T) {
+	primary := &client.Container{
+		Labels: map[string]interface{}{
+			launchConfigLabel: primaryLaunchConfig,
+		},
+	}
+	sidekick := &client.Container{
+		Labels: map[string]interface{}{
+			launchConfigLabel: "data",
+		},
+	}
+	unlabeled := &client.Container{}
+
+	assert.True(t, isLaunchConfig(primary, primaryLaunchConfig))
+	assert.False(t, isLaunchConfig(primary, "data"))
+	assert.True(t, isLaunchConfig(sidekick, "data"))
+	assert.False(t, isLaunchConfig(sidekick, primaryLaunchConfig))
+	assert.True(t, isLaunchConfig(unlabeled, primaryLaunchConfig))
+}
+
+func TestLogsInput(t *testing.T) {
+	assert.Equal(t, map[string]interface{}{"follow": true}, logsInput(options.Log{Follow: true, Tail: -1}))
+	assert.Equal(t, map[string]interface{}{"follow": false, "lines": 0}, logsInput(options.Log{Tail: 0}))
+	assert.Equal(t, map[string]interface{}{"follow": false, "lines": 10}, logsInput(options.Log{Tail: 10}))
+}
@@ -3,6 +3,8 @@ package rancher
 import (
 	"fmt"
 
+	"golang.org/x/net/context"
+
 	"github.com/Sirupsen/logrus"
 	"github.com/docker/libcompose/utils"
 	"github.com/rancher/go-rancher/v2"
@@ -17,6 +19,10 @@ func (f *NormalFactory) Hash(service *RancherService) (digest.ServiceHash, error
 	return hash, err
 }
 
+func (f *NormalFactory) Config(service *RancherService) (digest.ServiceHash, *CompositeService, error) {
+	return f.configAndHash(service)
+}
+
 func (f *NormalFactory) configAndHash(r *RancherService) (digest.ServiceHash, *CompositeService, error) {
 	rancherService, launchConfig, secondaryLaunchConfigs, err := f.config(r)
 	if err != nil {
@@ -102,7 +108,7 @@ func (f *NormalFactory) Create(r *RancherService) error {
 	return err
 }
 
-func (f *NormalFactory) Rollback(r *RancherService) error {
+func (f *NormalFactory) Rollback(ctx context.Context, r *RancherService) error {
 	existingService, err := r.FindExisting(r.Name())
 	if err != nil || existingService == nil {
 		return err
@@ -113,7 +119,7 @@ func (f *NormalFactory) Rollback(r *RancherService) error {
 		return err
 	}
 
-	return r.Wait(existingService)
+	return r.Wait(ctx, existingService)
 }
 
 func isForce(name string, force bool, selected []string) bool {
@@ -127,7 +133,7 @@ func isForce(name string, force bool, selected []string) bool {
 	return utils.Contains(selected, name)
 }
 
-func (f *NormalFactory) Upgrade(r *RancherService, force bool, selected []string) error {
+func (f *NormalFactory) Upgrade(ctx context.Context, r *RancherService, force bool, selected []string) error {
 	existingService, err := r.FindExisting(r.Name())
 	if err != nil || existingService == nil {
 		return err
@@ -163,10 +169,10 @@ func (f *NormalFactory) Upgrade(r *RancherService, force bool, selected []string
 		}
 	}
 
-	return f.upgrade(r, existingService, service, launchConfig, secondaryNames, removedSecondaryNames)
+	return f.upgrade(ctx, r, existingService, service, launchConfig, secondaryNames, removedSecondaryNames)
 }
 
-func (f *NormalFactory) upgrade(r *RancherService, existingService *client.Service, service, launchConfig bool, secondaryNames, removedSecondaryNames []string) error {
+func (f *NormalFactory) upgrade(ctx context.Context, r *RancherService, existingService *client.Service, service, launchConfig bool, secondaryNames, removedSecondaryNames []string) error {
 	_, config, err := f.configAndHash(r)
 	if err != nil {
 		return err
@@ -222,7 +228,7 @@ func (f *NormalFactory) upgrade(r *RancherService, existingService *client.Servi
 			return err
 		}
 
-		if err := r.Wait(existingService); err != nil {
+		if err := r.Wait(ctx, existingService); err != nil {
 			return err
 		}
 	}
@@ -235,7 +241,7 @@ func (f *NormalFactory) upgrade(r *RancherService, existingService *client.Servi
 		}
 	}
 
-	return r.Wait(existingService)
+	return r.Wait(ctx, existingService)
 }
 
 func convertNestedMapKeysToStrings(service map[string]interface{}) map[string]interface{} {
@@ -0,0 +1,234 @@
+package rancher
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"golang.org/x/net/context"
+
+	"github.com/docker/libcompose/utils"
+	"github.com/rancher/go-rancher/v2"
+	"github.com/rancher/rancher-compose-executor/digest"
+	"github.com/rancher/rancher-compose-executor/project"
+)
+
+// Launch config fields that are filled in by the server and never set from
+// the compose files, they are left out of diffs when not set locally.
+var serverLaunchConfigFields = map[string]bool{
+	"kind":          true,
+	"startOnCreate": true,
+	"type":          true,
+	"vcpu":          true,
+	"version":       true,
+}
+
+// Plan works out what up would do to the service without changing anything
+// on the server.
+func (r *RancherService) Plan(ctx context.Context) (*project.ServicePlan, error) {
+	plan := &project.ServicePlan{
+		Service: r.name,
+	}
+
+	service, err := r.FindExisting(r.name)
+	if err != nil {
+		return nil, err
+	}
+
+	if service == nil {
+		plan.Action = project.PlanCreate
+		plan.Reasons = []string{"Service does not exist"}
+		return plan, nil
+	}
+
+	factory, err := GetFactory(r)
+	if err != nil {
+		return nil, err
+	}
+
+	hash, config, err := factory.Config(r)
+	if err != nil {
+		return nil, err
+	}
+
+	existingHash, ok := digest.LookupHash(service)
+	if !ok {
+		plan.Reasons = append(plan.Reasons, "Service has no configuration hash")
+	} else if hash.Service != existingHash.Service {
+		plan.Reasons = append(plan.Reasons, "Service fields changed")
+	}
+
+	if hash.LaunchConfig != existingHash.LaunchConfig {
+		plan.Reasons = append(plan.Reasons, "Launch config changed")
+		diff, err := diffLaunchConfigs("launchConfig", service.LaunchConfig, config.LaunchConfig)
+		if err != nil {
+			return nil, err
+		}
+		plan.Diff = append(plan.Diff, diff...)
+	}
+
+	existingSecondaries := secondaryLaunchConfigsByName(service.SecondaryLaunchConfigs)
+	secondaries := secondaryLaunchConfigsByName(config.SecondaryLaunchConfigs)
+
+	for _, name := range sortedKeys(hash.SecondaryLaunchConfigs, existingHash.SecondaryLaunchConfigs) {
+		newHash, inLocal := hash.SecondaryLaunchConfigs[name]
+		oldHash, inExisting := existingHash.SecondaryLaunchConfigs[name]
+		switch {
+		case !inExisting:
+			plan.Reasons = append(plan.Reasons, fmt.Sprintf("Sidekick %s added", name))
+		case !inLocal:
+			plan.Reasons = append(plan.Reasons, fmt.Sprintf("Sidekick %s removed", name))
+		case oldHash != newHash:
+			plan.Reasons = append(plan.Reasons, fmt.Sprintf("Sidekick %s changed", name))
+			diff, err := diffLaunchConfigs("secondaryLaunchConfigs."+name, existingSecondaries[name], secondaries[name])
+			if err != nil {
+				return nil, err
+			}
+			plan.Diff = append(plan.Diff, diff...)
+		}
+	}
+
+	r.planAction(plan)
+	return plan, nil
+}
+
+// planAction sets the action up takes for the reasons found, up only upgrades
+// out of sync services with --upgrade, see shouldUpgrade.
+func (r *RancherService) planAction(plan *project.ServicePlan) {
+	switch {
+	case !r.canUpgrade():
+		plan.Action = project.PlanUnchanged
+		if len(plan.Reasons) > 0 {
+			plan.Reasons = append(plan.Reasons, "Service type is not upgraded by up")
+		}
+	case r.context.ForceUpgrade:
+		plan.Action = project.PlanUpgrade
+		if len(plan.Reasons) == 0 {
+			plan.Reasons = []string{"Upgrade forced"}
+		}
+	case len(plan.Reasons) == 0:
+		plan.Action = project.PlanUnchanged
+	case r.context.Upgrade:
+		plan.Action = project.PlanUpgrade
+	default:
+		plan.Action = project.PlanNeedsUpgrade
+	}
+}
+
+func (r *RancherService) canUpgrade() bool {
+	switch FindServiceType(r) {
+	case ExternalServiceType, DnsServiceType:
+		return false
+	}
+	return true
+}
+
+func secondaryLaunchConfigsByName(secondaryLaunchConfigs []client.SecondaryLaunchConfig) map[string]client.SecondaryLaunchConfig {
+	result := map[string]client.SecondaryLaunchConfig{}
+	for _, secondaryLaunchConfig := range secondaryLaunchConfigs {
+		result[secondaryLaunchConfig.Name] = secondaryLaunchConfig
+	}
+	return result
+}
+
+func sortedKeys(maps ...map[string]string) []string {
+	seen := map[string]bool{}
+	keys := []string{}
+	for _, m := range maps {
+		for key := range m {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// diffLaunchConfigs lists the fields that differ between the launch config on
+// the server and the one computed from the compose files.
+func diffLaunchConfigs(path string, existing, local interface{}) ([]string, error) {
+	existingMap := map[string]interface{}{}
+	if err := utils.ConvertByJSON(existing, &existingMap); err != nil {
+		return nil, err
+	}
+
+	localMap := map[string]interface{}{}
+	if err := utils.ConvertByJSON(local, &localMap); err != nil {
+		return nil, err
+	}
+
+	for _, m := range []map[string]interface{}{existingMap, localMap} {
+		if labels, ok := m["labels"].(map[string]interface{}); ok {
+			delete(labels, digest.ServiceHashKey)
+		}
+	}
+
+	for key := range existingMap {
+		if serverLaunchConfigFields[key] && isEmptyValue(localMap[key]) {
+			delete(existingMap, key)
+		}
+	}
+
+	return diffValues(path, existingMap, localMap), nil
+}
+
+func diffValues(path string, existing, local interface{}) []string {
+	existingMap, existingIsMap := existing.(map[string]interface{})
+	localMap, localIsMap := local.(map[string]interface{})
+
+	if existingIsMap && localIsMap {
+		result := []string{}
+		for _, key := range sortedMapKeys(existingMap, localMap) {
+			result = append(result, diffValues(path+"."+key, existingMap[key], localMap[key])...)
+		}
+		return result
+	}
+
+	if isEmptyValue(existing) && isEmptyValue(local) || reflect.DeepEqual(existing, local) {
+		return nil
+	}
+
+	return []string{fmt.Sprintf("%s: %s => %s", path, toJSON(existing), toJSON(local))}
+}
+
+func sortedMapKeys(maps ...map[string]interface{}) []string {
+	keys := []map[string]string{}
+	for _, m := range maps {
+		set := map[string]string{}
+		for key := range m {
+			set[key] = ""
+		}
+		keys = append(keys, set)
+	}
+	return sortedKeys(keys...)
+}
+
+func isEmptyValue(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Float64:
+		return v.Float() == 0
+	}
+	return false
+}
+
+func toJSON(value interface{}) string {
+	if value == nil {
+		return "<none>"
+	}
+	bytes, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(bytes)
+}
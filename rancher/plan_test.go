@@ -0,0 +1,71 @@
+package rancher
+
+import (
+	"testing"
+
+	"github.com/rancher/go-rancher/v2"
+	"github.com/rancher/rancher-compose-executor/config"
+	"github.com/rancher/rancher-compose-executor/digest"
+	"github.com/rancher/rancher-compose-executor/project"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffLaunchConfigs(t *testing.T) {
+	existing := &client.LaunchConfig{
+		ImageUuid:     "docker:nginx:1.10",
+		Kind:          "container",
+		StartOnCreate: true,
+		Environment: map[string]interface{}{
+			"A": "1",
+		},
+		Labels: map[string]interface{}{
+			digest.ServiceHashKey: "abc",
+			"foo":                 "bar",
+		},
+	}
+	local := &client.LaunchConfig{
+		ImageUuid: "docker:nginx:1.11",
+		Environment: map[string]interface{}{
+			"A": "1",
+			"B": "2",
+		},
+		Labels: map[string]interface{}{
+			"foo": "bar",
+		},
+	}
+
+	diff, err := diffLaunchConfigs("launchConfig", existing, local)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		`launchConfig.environment.B: <none> => "2"`,
+		`launchConfig.imageUuid: "docker:nginx:1.10" => "docker:nginx:1.11"`,
+	}, diff)
+
+	diff, err = diffLaunchConfigs("launchConfig", existing, existing)
+	assert.Nil(t, err)
+	assert.Empty(t, diff)
+}
+
+func TestPlanAction(t *testing.T) {
+	for _, test := range []struct {
+		image                 string
+		upgrade, forceUpgrade bool
+		reasons               []string
+		action                project.PlanAction
+	}{
+		{image: "nginx", action: project.PlanUnchanged},
+		{image: "nginx", reasons: []string{"Launch config changed"}, action: project.PlanNeedsUpgrade},
+		{image: "nginx", upgrade: true, reasons: []string{"Launch config changed"}, action: project.PlanUpgrade},
+		{image: "nginx", upgrade: true, action: project.PlanUnchanged},
+		{image: "nginx", forceUpgrade: true, action: project.PlanUpgrade},
+		{image: EXTERNAL_IMAGE, upgrade: true, reasons: []string{"Service fields changed"}, action: project.PlanUnchanged},
+	} {
+		r := &RancherService{
+			serviceConfig: &config.ServiceConfig{Image: test.image},
+			context:       &Context{Upgrade: test.upgrade, ForceUpgrade: test.forceUpgrade},
+		}
+		plan := &project.ServicePlan{Reasons: test.reasons}
+		r.planAction(plan)
+		assert.Equal(t, test.action, plan.Action, "%+v", test)
+	}
+}
@@ -6,6 +6,36 @@ import (
 )
 
 func NewProject(context *Context) (*project.Project, error) {
+	p, err := newProject(context)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := context.open(); err != nil {
+		logrus.Errorf("Failed to open project %s: %v", p.Name, err)
+		return nil, err
+	}
+	p.Name = context.ProjectName
+
+	context.SidekickInfo = NewSidekickInfo(p)
+
+	return p, nil
+}
+
+// NewOfflineProject parses the project without connecting to the server, the
+// project can be inspected but no command can be run on it.
+func NewOfflineProject(context *Context) (*project.Project, error) {
+	p, err := newProject(context)
+	if err != nil {
+		return nil, err
+	}
+
+	context.SidekickInfo = NewSidekickInfo(p)
+
+	return p, nil
+}
+
+func newProject(context *Context) (*project.Project, error) {
 	context.ServiceFactory = &RancherServiceFactory{
 		Context: context,
 	}
@@ -30,18 +60,15 @@ func NewProject(context *Context) (*project.Project, error) {
 		Context: context,
 	}
 
+	context.Runtime = &RancherRuntime{
+		Context: context,
+	}
+
 	p := project.NewProject(&context.Context)
 
 	if err := p.Parse(); err != nil {
 		return nil, err
 	}
-	if err := context.open(); err != nil {
-		logrus.Errorf("Failed to open project %s: %v", p.Name, err)
-		return nil, err
-	}
-	p.Name = context.ProjectName
-
-	context.SidekickInfo = NewSidekickInfo(p)
 
 	return p, nil
 }
@@ -0,0 +1,200 @@
+package rancher
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/libcompose/utils"
+	"github.com/rancher/go-rancher/v2"
+	"github.com/rancher/rancher-compose-executor/config"
+	"github.com/rancher/rancher-compose-executor/project/options"
+	"github.com/rancher/rancher-compose-executor/yaml"
+)
+
+const startOnceLabel = "io.rancher.container.start_once"
+
+func (r *RancherService) Run(ctx context.Context, commandParts []string, options options.Run) (int, error) {
+	return r.run(ctx, r.name, r.serviceConfig, commandParts, options)
+}
+
+// run creates a standalone container in the stack from the launch config of
+// the given service, with the command replaced when one is given. Unless
+// detached, the logs of the container are streamed until it stops and its
+// exit code is returned.
+func (r *RancherService) run(ctx context.Context, name string, serviceConfig *config.ServiceConfig, commandParts []string, options options.Run) (exitCode int, err error) {
+	launchConfig, err := createLaunchConfig(r, name, runServiceConfig(serviceConfig, commandParts, options))
+	if err != nil {
+		return 1, err
+	}
+
+	suffix, err := randomSuffix()
+	if err != nil {
+		return 1, err
+	}
+
+	var container client.Container
+	if err := utils.Convert(launchConfig, &container); err != nil {
+		return 1, err
+	}
+	container.Name = fmt.Sprintf("%s-%s-run-%s", r.context.ProjectName, name, suffix)
+	container.StackId = r.context.Stack.Id
+	container.StartOnCreate = true
+	container.RestartPolicy = &client.RestartPolicy{Name: "no"}
+	if container.Labels == nil {
+		container.Labels = map[string]interface{}{}
+	}
+	container.Labels[startOnceLabel] = "true"
+
+	logrus.Infof("Creating container %s", container.Name)
+	created, err := r.context.Client.Container.Create(&container)
+	if err != nil {
+		return 1, err
+	}
+
+	// However run ends the container is removed, with a context of its own
+	// since ctx is cancelled on interrupt
+	if options.Remove {
+		defer func() {
+			if removeErr := r.removeContainer(context.Background(), created); removeErr != nil && err == nil {
+				err = removeErr
+			}
+		}()
+	}
+
+	if err := r.waitContainer(ctx, created); err != nil {
+		return 1, err
+	}
+
+	if created.State == "error" || created.Transitioning == "error" {
+		return 1, fmt.Errorf("Failed to start %s: %s", created.Name, created.TransitioningMessage)
+	}
+
+	if options.Detached {
+		if options.Stdout != nil {
+			fmt.Fprintln(options.Stdout, created.Name)
+		}
+		return 0, nil
+	}
+
+	r.followRunLogs(ctx, created)
+
+	for created.State == "running" || created.Transitioning == "yes" {
+		select {
+		case <-ctx.Done():
+			return 1, ctx.Err()
+		case <-time.After(logsReconnectDelay):
+		}
+
+		if err := r.context.Client.Reload(&created.Resource, created); err != nil {
+			return 1, err
+		}
+	}
+
+	exitCode, ok := containerExitCode(created)
+	if !ok {
+		return exitCode, fmt.Errorf("Failed to find the exit code of %s, it is %s", created.Name, created.State)
+	}
+	return exitCode, nil
+}
+
+// runServiceConfig copies the config of the service for a one-off container.
+// The ports are left out unless asked for, the containers of the service hold
+// them already.
+func runServiceConfig(serviceConfig *config.ServiceConfig, commandParts []string, options options.Run) *config.ServiceConfig {
+	runConfig := *serviceConfig
+	if !options.ServicePorts {
+		runConfig.Ports = nil
+	}
+	if len(commandParts) > 0 {
+		runConfig.Command = yaml.Command(commandParts)
+	}
+	return &runConfig
+}
+
+// followRunLogs streams the logs of a one-off container for as long as it is
+// running, the websocket is opened again if it drops before the container
+// stops.
+func (r *RancherService) followRunLogs(ctx context.Context, container *client.Container) {
+	logName := strings.TrimPrefix(container.Name, r.context.ProjectName+"-")
+	logger := r.context.LoggerFactory.CreateContainerLogger(logName)
+	logOptions := options.Log{
+		Follow: true,
+	}
+
+	var last time.Time
+	for {
+		conn, err := r.context.hostAccess(container.Resource, "logs", &client.ContainerLogs{
+			Follow: true,
+		})
+		if err != nil {
+			logrus.Errorf("Failed to get logs for %s: %v", container.Name, err)
+		} else {
+			last = r.pipeLogs(ctx, logger, conn, last, logOptions)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(logsReconnectDelay):
+		}
+
+		if err := r.context.Client.Reload(&container.Resource, container); err != nil || container.State != "running" {
+			return
+		}
+	}
+}
+
+func (r *RancherService) waitContainer(ctx context.Context, container *client.Container) error {
+	return r.WaitFor(ctx, &container.Resource, container, func() string {
+		return container.Transitioning
+	})
+}
+
+func (r *RancherService) removeContainer(ctx context.Context, container *client.Container) error {
+	logrus.Infof("Removing container %s", container.Name)
+	removed, err := r.context.Client.Container.ActionRemove(container)
+	if err != nil {
+		return err
+	}
+
+	return r.waitInstance(ctx, removed)
+}
+
+// containerExitCode reads the exit code the agent reported in the docker
+// inspect data of a stopped container.
+func containerExitCode(container *client.Container) (int, bool) {
+	for _, path := range [][]string{
+		{"dockerInspect", "State", "ExitCode"},
+		{"fields", "dockerInspect", "State", "ExitCode"},
+	} {
+		var value interface{} = container.Data
+		for _, key := range path {
+			m, ok := value.(map[string]interface{})
+			if !ok {
+				value = nil
+				break
+			}
+			value = m[key]
+		}
+
+		if exitCode, ok := value.(float64); ok {
+			return int(exitCode), true
+		}
+	}
+
+	return 0, false
+}
+
+func randomSuffix() (string, error) {
+	suffix := make([]byte, 3)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(suffix), nil
+}
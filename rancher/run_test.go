@@ -0,0 +1,28 @@
+package rancher
+
+import (
+	"testing"
+
+	"github.com/rancher/rancher-compose-executor/config"
+	"github.com/rancher/rancher-compose-executor/project/options"
+	"github.com/rancher/rancher-compose-executor/yaml"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunServiceConfig(t *testing.T) {
+	serviceConfig := &config.ServiceConfig{
+		Image:   "nginx",
+		Command: yaml.Command{"nginx"},
+		Ports:   []string{"80:80"},
+	}
+
+	runConfig := runServiceConfig(serviceConfig, []string{"sh"}, options.Run{})
+	assert.Equal(t, "nginx", runConfig.Image)
+	assert.Equal(t, yaml.Command{"sh"}, runConfig.Command)
+	assert.Empty(t, runConfig.Ports)
+
+	runConfig = runServiceConfig(serviceConfig, nil, options.Run{ServicePorts: true})
+	assert.Equal(t, yaml.Command{"nginx"}, runConfig.Command)
+	assert.Equal(t, []string{"80:80"}, runConfig.Ports)
+	assert.Equal(t, []string{"80:80"}, serviceConfig.Ports)
+}
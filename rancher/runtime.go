@@ -0,0 +1,63 @@
+package rancher
+
+import (
+	"sort"
+
+	"golang.org/x/net/context"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/rancher/go-rancher/v2"
+	"github.com/rancher/rancher-compose-executor/config"
+	"github.com/rancher/rancher-compose-executor/project/options"
+)
+
+type RancherRuntime struct {
+	Context *Context
+}
+
+// Orphans returns the names of the services of the stack that are no longer
+// defined in the compose files.
+func (r *RancherRuntime) Orphans(ctx context.Context, projectName string, serviceConfigs *config.ServiceConfigs) ([]string, error) {
+	orphans := []string{}
+	if r.Context.Stack.Id == "" {
+		return orphans, nil
+	}
+
+	services, err := r.Context.Client.Service.List(&client.ListOpts{
+		Filters: map[string]interface{}{
+			"stackId":      r.Context.Stack.Id,
+			"removed_null": nil,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, service := range services.Data {
+		if _, ok := serviceConfigs.Get(service.Name); !ok {
+			orphans = append(orphans, service.Name)
+		}
+	}
+
+	sort.Strings(orphans)
+	return orphans, nil
+}
+
+// RemoveOrphans deletes the services of the stack that are no longer defined
+// in the compose files.
+func (r *RancherRuntime) RemoveOrphans(ctx context.Context, projectName string, serviceConfigs *config.ServiceConfigs) error {
+	orphans, err := r.Orphans(ctx, projectName, serviceConfigs)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range orphans {
+		logrus.Infof("Found orphan service %s in project %s", name, projectName)
+		orphan := NewService(name, &config.ServiceConfig{}, r.Context)
+		if err := orphan.Delete(ctx, options.Delete{RemoveRunning: true}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
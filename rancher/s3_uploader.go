@@ -5,6 +5,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"time"
 
@@ -15,6 +16,15 @@ import (
 	"github.com/rancher/rancher-compose-executor/project"
 )
 
+// s3Client keeps the uploads to S3 away from the default transport of
+// net/http, which carries the TLS and proxy settings of the Rancher API.
+var s3Client = &http.Client{
+	Transport: &http.Transport{
+		Proxy:               http.ProxyFromEnvironment,
+		TLSHandshakeTimeout: 10 * time.Second,
+	},
+}
+
 type S3Uploader struct {
 }
 
@@ -23,15 +33,7 @@ func (s *S3Uploader) Name() string {
 }
 
 func (s *S3Uploader) Upload(p *project.Project, name string, reader io.ReadSeeker, hash string) (string, string, error) {
-	bucketName := fmt.Sprintf("%s-%s", p.Name, someHash())
-	objectKey := fmt.Sprintf("%s-%s", name, hash[:12])
-
-	config := aws.DefaultConfig.Copy()
-	if config.Region == "" {
-		config.Region = "us-east-1"
-	}
-
-	svc := s3.New(&config)
+	svc, bucketName, objectKey := s.locate(p, name, hash)
 
 	if err := getOrCreateBucket(svc, bucketName); err != nil {
 		return "", "", err
@@ -41,13 +43,47 @@ func (s *S3Uploader) Upload(p *project.Project, name string, reader io.ReadSeeke
 		return "", "", err
 	}
 
-	req, _ := svc.GetObjectRequest(&s3.GetObjectInput{
+	url, err := presign(svc, bucketName, objectKey)
+	return objectKey, url, err
+}
+
+func (s *S3Uploader) Uploaded(p *project.Project, name string, hash string) (string, string, bool, error) {
+	svc, bucketName, objectKey := s.locate(p, name, hash)
+
+	_, err := svc.HeadObject(&s3.HeadObjectInput{
 		Bucket: &bucketName,
 		Key:    &objectKey,
 	})
+	if reqErr, ok := err.(awserr.RequestFailure); ok && reqErr.StatusCode() == 404 {
+		return "", "", false, nil
+	} else if err != nil {
+		return "", "", false, err
+	}
 
-	url, err := req.Presign(24 * 7 * time.Hour)
-	return objectKey, url, err
+	url, err := presign(svc, bucketName, objectKey)
+	return objectKey, url, err == nil, err
+}
+
+func (s *S3Uploader) locate(p *project.Project, name string, hash string) (*s3.S3, string, string) {
+	bucketName := fmt.Sprintf("%s-%s", p.Name, someHash())
+	objectKey := fmt.Sprintf("%s-%s", name, hash[:12])
+
+	config := aws.DefaultConfig.Copy()
+	config.HTTPClient = s3Client
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+
+	return s3.New(&config), bucketName, objectKey
+}
+
+func presign(svc *s3.S3, bucket, object string) (string, error) {
+	req, _ := svc.GetObjectRequest(&s3.GetObjectInput{
+		Bucket: &bucket,
+		Key:    &object,
+	})
+
+	return req.Presign(24 * 7 * time.Hour)
 }
 
 func putFile(svc *s3.S3, bucket, object string, reader io.ReadSeeker) error {
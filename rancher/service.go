@@ -3,7 +3,7 @@ package rancher
 import (
 	"errors"
 	"fmt"
-	"io"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -13,12 +13,11 @@ import (
 	"github.com/Sirupsen/logrus"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/libcompose/labels"
-	"github.com/gorilla/websocket"
-	"github.com/rancher/go-rancher/hostaccess"
 	"github.com/rancher/go-rancher/v2"
 	"github.com/rancher/rancher-compose-executor/config"
 	"github.com/rancher/rancher-compose-executor/docker/service"
 	"github.com/rancher/rancher-compose-executor/project"
+	"github.com/rancher/rancher-compose-executor/project/events"
 	"github.com/rancher/rancher-compose-executor/project/options"
 	rUtils "github.com/rancher/rancher-compose-executor/utils"
 )
@@ -69,7 +68,7 @@ func (r *RancherService) Create(ctx context.Context, options options.Create) err
 	service, err := r.FindExisting(r.name)
 
 	if err == nil && service == nil {
-		service, err = r.createService()
+		service, err = r.createService(ctx)
 	} else if err == nil && service != nil {
 		err = r.setupLinks(service, service.State == "inactive")
 	}
@@ -77,15 +76,131 @@ func (r *RancherService) Create(ctx context.Context, options options.Create) err
 	return err
 }
 
+func (r *RancherService) Delete(ctx context.Context, options options.Delete) error {
+	service, err := r.FindExisting(r.name)
+	if err != nil || service == nil {
+		return err
+	}
+
+	if service.Removed != "" || service.State == "removing" || service.State == "removed" {
+		return nil
+	}
+
+	if service.State == "active" && !options.RemoveRunning {
+		return fmt.Errorf("Service %s is active, refusing to delete a running service", r.name)
+	}
+
+	logrus.Infof("Deleting service %s", r.name)
+	service, err = r.context.Client.Service.ActionRemove(service)
+	if err != nil {
+		return err
+	}
+
+	return r.Wait(ctx, service)
+}
+
+func (r *RancherService) Start(ctx context.Context) error {
+	return r.up(ctx, false)
+}
+
+func (r *RancherService) Stop(ctx context.Context) error {
+	service, err := r.FindExisting(r.name)
+	if err != nil || service == nil {
+		return err
+	}
+
+	if service.State == "inactive" || service.Actions["deactivate"] == "" {
+		return nil
+	}
+
+	service, err = r.context.Client.Service.ActionDeactivate(service)
+	if err != nil {
+		return err
+	}
+
+	return r.Wait(ctx, service)
+}
+
+func (r *RancherService) Restart(ctx context.Context) error {
+	service, err := r.FindExisting(r.name)
+	if err != nil {
+		return err
+	}
+
+	if service == nil {
+		return fmt.Errorf("Failed to find service %s", r.name)
+	}
+
+	if service.Actions["restart"] == "" {
+		return fmt.Errorf("Service %s can not be restarted, currently: state=%s", r.name, service.State)
+	}
+
+	service, err = r.context.Client.Service.ActionRestart(service, &client.ServiceRestart{
+		RollingRestartStrategy: client.RollingRestartStrategy{
+			BatchSize:      r.context.BatchSize,
+			IntervalMillis: r.context.Interval,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	return r.Wait(ctx, service)
+}
+
+func (r *RancherService) Scale(ctx context.Context, count int) error {
+	if r.serviceConfig.Labels["io.rancher.scheduler.global"] == "true" {
+		return fmt.Errorf("Service %s is scheduled globally and can not be scaled", r.name)
+	}
+
+	service, err := r.FindExisting(r.name)
+	if err != nil {
+		return err
+	}
+
+	if service == nil {
+		return fmt.Errorf("Failed to find %s to scale", r.name)
+	}
+
+	if service.Scale != int64(count) {
+		service, err = r.context.Client.Service.Update(service, map[string]interface{}{
+			"scale": count,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := r.Wait(ctx, service); err != nil {
+		return err
+	}
+
+	containers, err := r.containers()
+	if err != nil {
+		return err
+	}
+
+	// Sidekicks run next to every instance, only the primaries are counted
+	instances := 0
+	for i := range containers {
+		if isLaunchConfig(&containers[i], primaryLaunchConfig) {
+			instances++
+		}
+	}
+
+	logrus.Infof("Service %s has scale %d with %d instances", r.name, service.Scale, instances)
+	return nil
+}
+
 func (r *RancherService) Up(ctx context.Context, options options.Up) error {
-	return r.up(true)
+	return r.up(ctx, true)
 }
 
 func (r *RancherService) Build(ctx context.Context, buildOptions options.Build) error {
-	return nil
+	return r.build(ctx, buildOptions)
 }
 
-func (r *RancherService) up(create bool) error {
+func (r *RancherService) up(ctx context.Context, create bool) error {
 	service, err := r.FindExisting(r.name)
 	if err != nil {
 		return err
@@ -96,13 +211,13 @@ func (r *RancherService) up(create bool) error {
 			return nil
 		}
 
-		_, err := r.rollback(service)
+		_, err := r.rollback(ctx, service)
 		return err
 	}
 
 	if service != nil && create && r.shouldUpgrade(service) {
 		if r.context.Pull {
-			if err := r.Pull(context.Background()); err != nil {
+			if err := r.Pull(ctx); err != nil {
 				return err
 			}
 		}
@@ -112,16 +227,18 @@ func (r *RancherService) up(create bool) error {
 			if err != nil {
 				return err
 			}
-			err = r.Wait(service)
+			err = r.Wait(ctx, service)
 			if err != nil {
 				return err
 			}
 		}
 
-		service, err = r.upgrade(service, r.context.ForceUpgrade, r.context.Args)
+		r.context.Project.Notify(events.ServiceUpgradeStart, r.name, nil)
+		service, err = r.upgrade(ctx, service, r.context.ForceUpgrade, r.context.Args)
 		if err != nil {
 			return err
 		}
+		r.context.Project.Notify(events.ServiceUpgrade, r.name, nil)
 	}
 
 	if service == nil && !create {
@@ -129,7 +246,7 @@ func (r *RancherService) up(create bool) error {
 	}
 
 	if service == nil {
-		service, err = r.createService()
+		service, err = r.createService(ctx)
 	} else {
 		err = r.setupLinks(service, true)
 	}
@@ -143,7 +260,7 @@ func (r *RancherService) up(create bool) error {
 		if err != nil {
 			return err
 		}
-		err = r.Wait(service)
+		err = r.Wait(ctx, service)
 		if err != nil {
 			return err
 		}
@@ -155,23 +272,29 @@ func (r *RancherService) up(create bool) error {
 
 	if service.Actions["activate"] != "" {
 		service, err = r.context.Client.Service.ActionActivate(service)
-		err = r.Wait(service)
+		err = r.Wait(ctx, service)
 	}
 
 	// TODO: revisit whether this is the best place to perform this check
 	if _, ok := r.serviceConfig.Labels["io.rancher.service.wait_for_healthcheck"]; ok {
 		logrus.Debugf("Detected label io.rancher.service.wait_for_healthcheck. Polling for health")
+		r.context.Project.Notify(events.ServiceHealthCheckStart, r.name, nil)
 		for {
 			logrus.Debugf("Service %s has health state %s", service.Name, service.HealthState)
 			if service.HealthState == "healthy" {
 				break
 			}
-			time.Sleep(150 * time.Millisecond)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(150 * time.Millisecond):
+			}
 			err := r.context.Client.Reload(&service.Resource, service)
 			if err != nil {
 				return err
 			}
 		}
+		r.context.Project.Notify(events.ServiceHealthCheck, r.name, nil)
 	}
 	return err
 }
@@ -204,7 +327,7 @@ func (r *RancherService) FindExisting(name string) (*client.Service, error) {
 	logrus.Debugf("Finding service %s", name)
 
 	name, stackId, err := r.resolveServiceAndStackId(name)
-	if err != nil {
+	if err != nil || stackId == "" {
 		return nil, err
 	}
 
@@ -249,7 +372,7 @@ func (r *RancherService) getConfiguredScale() int {
 	return 1
 }
 
-func (r *RancherService) createService() (*client.Service, error) {
+func (r *RancherService) createService(ctx context.Context) (*client.Service, error) {
 	logrus.Infof("Creating service %s", r.name)
 
 	factory, err := GetFactory(r)
@@ -270,7 +393,7 @@ func (r *RancherService) createService() (*client.Service, error) {
 		return nil, err
 	}
 
-	return service, r.Wait(service)
+	return service, r.Wait(ctx, service)
 }
 
 func (r *RancherService) setupLinks(service *client.Service, update bool) error {
@@ -362,7 +485,7 @@ func (r *RancherService) getLinks() (map[Link]string, error) {
 
 func (r *RancherService) containers() ([]client.Container, error) {
 	service, err := r.FindExisting(r.name)
-	if err != nil {
+	if err != nil || service == nil {
 		return nil, err
 	}
 
@@ -376,69 +499,6 @@ func (r *RancherService) containers() ([]client.Container, error) {
 	return instances.Data, nil
 }
 
-func (r *RancherService) Log(ctx context.Context, follow bool) error {
-	service, err := r.FindExisting(r.name)
-	if err != nil || service == nil {
-		return err
-	}
-
-	if service.Type != "service" {
-		return nil
-	}
-
-	containers, err := r.containers()
-	if err != nil {
-		logrus.Errorf("Failed to list containers to log: %v", err)
-		return err
-	}
-
-	for _, container := range containers {
-		websocketClient := (*hostaccess.RancherWebsocketClient)(r.context.Client)
-		conn, err := websocketClient.GetHostAccess(container.Resource, "logs", nil)
-		if err != nil {
-			logrus.Errorf("Failed to get logs for %s: %v", container.Name, err)
-			continue
-		}
-
-		go r.pipeLogs(&container, conn)
-	}
-
-	return nil
-}
-
-func (r *RancherService) pipeLogs(container *client.Container, conn *websocket.Conn) {
-	defer conn.Close()
-
-	log_name := strings.TrimPrefix(container.Name, r.context.ProjectName+"_")
-	logger := r.context.LoggerFactory.CreateContainerLogger(log_name)
-
-	for {
-		messageType, bytes, err := conn.ReadMessage()
-
-		if err == io.EOF {
-			return
-		} else if err != nil {
-			logrus.Errorf("Failed to read log: %v", err)
-			return
-		}
-
-		if messageType != websocket.TextMessage || len(bytes) <= 3 {
-			continue
-		}
-
-		if bytes[len(bytes)-1] != '\n' {
-			bytes = append(bytes, '\n')
-		}
-		message := bytes[3:]
-
-		if "01" == string(bytes[:2]) {
-			logger.Out(message)
-		} else {
-			logger.Err(message)
-		}
-	}
-}
-
 func (r *RancherService) DependentServices() []project.ServiceRelationship {
 	result := []project.ServiceRelationship{}
 
@@ -466,7 +526,10 @@ func (r *RancherService) Client() *client.RancherClient {
 	return r.context.Client
 }
 
-func (r *RancherService) pullImage(image string, labels map[string]string) error {
+func (r *RancherService) pullImage(ctx context.Context, image string, labels map[string]string) error {
+	r.context.acquirePullSlot()
+	defer r.context.releasePullSlot()
+
 	taskOpts := &client.PullTask{
 		Mode:   "all",
 		Labels: rUtils.ToMapInterface(labels),
@@ -484,7 +547,7 @@ func (r *RancherService) pullImage(image string, labels map[string]string) error
 
 	printed := map[string]string{}
 	lastMessage := ""
-	r.WaitFor(&task.Resource, task, func() string {
+	err = r.WaitFor(ctx, &task.Resource, task, func() string {
 		if task.TransitioningMessage != "" && task.TransitioningMessage != "In Progress" && task.TransitioningMessage != lastMessage {
 			printStatus(task.Image, printed, task.Status)
 			lastMessage = task.TransitioningMessage
@@ -492,23 +555,37 @@ func (r *RancherService) pullImage(image string, labels map[string]string) error
 
 		return task.Transitioning
 	})
+	if err != nil {
+		return err
+	}
+
+	for host, status := range task.Status {
+		r.context.Project.Notify(events.ServicePull, r.name, map[string]string{
+			"image":  image,
+			"host":   host,
+			"status": fmt.Sprint(status),
+		})
+	}
 
 	if task.Transitioning == "error" {
 		return errors.New(task.TransitioningMessage)
 	}
 
 	if !printStatus(task.Image, printed, task.Status) {
-		return errors.New("Pull failed on one of the hosts")
+		return fmt.Errorf("Pull of %s failed on hosts: %s", image, strings.Join(failedHosts(task.Status), ", "))
 	}
 
 	logrus.Infof("Finished pulling %s", task.Image)
 	return nil
 }
 
-func (r *RancherService) Pull(ctx context.Context) (err error) {
+// Pull pulls the images of the service and its sidekicks on the hosts. The
+// images are pulled concurrently and every failure is reported in the
+// returned error.
+func (r *RancherService) Pull(ctx context.Context) error {
 	config := r.Config()
 	if config.Image == "" || FindServiceType(r) != RancherType {
-		return
+		return nil
 	}
 
 	toPull := map[string]bool{config.Image: true}
@@ -529,19 +606,39 @@ func (r *RancherService) Pull(ctx context.Context) (err error) {
 	}
 
 	wg := sync.WaitGroup{}
+	mutex := sync.Mutex{}
+	failures := []string{}
 
 	for image := range toPull {
 		wg.Add(1)
 		go func(image string) {
-			if pErr := r.pullImage(image, labels); pErr != nil {
-				err = pErr
+			defer wg.Done()
+			if err := r.pullImage(ctx, image, labels); err != nil {
+				mutex.Lock()
+				failures = append(failures, err.Error())
+				mutex.Unlock()
 			}
-			wg.Done()
 		}(image)
 	}
 
 	wg.Wait()
-	return
+
+	if len(failures) > 0 {
+		sort.Strings(failures)
+		return fmt.Errorf("Failed to pull images for %s:\n%s", r.name, strings.Join(failures, "\n"))
+	}
+	return nil
+}
+
+func failedHosts(status map[string]interface{}) []string {
+	hosts := []string{}
+	for host, objStatus := range status {
+		if objStatus != "Done" {
+			hosts = append(hosts, fmt.Sprintf("%s (%v)", host, objStatus))
+		}
+	}
+	sort.Strings(hosts)
+	return hosts
 }
 
 func appendHash(service *RancherService, existingLabels map[string]interface{}) (map[string]interface{}, error) {
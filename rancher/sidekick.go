@@ -1,10 +1,16 @@
 package rancher
 
 import (
+	"fmt"
+	"strings"
+	"sync"
+
 	"golang.org/x/net/context"
 
+	"github.com/Sirupsen/logrus"
 	"github.com/rancher/rancher-compose-executor/config"
 	"github.com/rancher/rancher-compose-executor/project"
+	"github.com/rancher/rancher-compose-executor/project/options"
 )
 
 type Sidekick struct {
@@ -56,6 +62,78 @@ func (s *Sidekick) DependentServices() []project.ServiceRelationship {
 	return dependentServices
 }
 
-func (s *Sidekick) Log(ctx context.Context, follow bool) error {
+func (s *Sidekick) Build(ctx context.Context, buildOptions options.Build) error {
+	return NewService(s.name, s.serviceConfig, s.context).build(ctx, buildOptions)
+}
+
+func (s *Sidekick) Scale(ctx context.Context, count int) error {
+	return fmt.Errorf("Service %s is a sidekick of %s, scale the primary service instead", s.name, strings.Join(s.primaries(), ", "))
+}
+
+func (s *Sidekick) Log(ctx context.Context, options options.Log) error {
+	wg := sync.WaitGroup{}
+	for _, primary := range s.primaries() {
+		serviceConfig, ok := s.context.Project.ServiceConfigs.Get(primary)
+		if !ok {
+			continue
+		}
+
+		wg.Add(1)
+		go func(primary *RancherService) {
+			defer wg.Done()
+			if err := primary.logLaunchConfig(ctx, s.name, options); err != nil {
+				logrus.Errorf("Failed to get logs for %s: %v", s.name, err)
+			}
+		}(NewService(primary, serviceConfig, s.context))
+	}
+	wg.Wait()
+
 	return nil
 }
+
+func (s *Sidekick) Exec(ctx context.Context, commandParts []string, options options.Exec) (int, error) {
+	for _, primary := range s.primaries() {
+		serviceConfig, ok := s.context.Project.ServiceConfigs.Get(primary)
+		if !ok {
+			continue
+		}
+
+		return NewService(primary, serviceConfig, s.context).execLaunchConfig(ctx, s.name, commandParts, options)
+	}
+
+	return 1, fmt.Errorf("Failed to find the primary service of sidekick %s", s.name)
+}
+
+func (s *Sidekick) Run(ctx context.Context, commandParts []string, options options.Run) (int, error) {
+	for _, primary := range s.primaries() {
+		serviceConfig, ok := s.context.Project.ServiceConfigs.Get(primary)
+		if !ok {
+			continue
+		}
+
+		return NewService(primary, serviceConfig, s.context).run(ctx, s.name, s.serviceConfig, commandParts, options)
+	}
+
+	return 1, fmt.Errorf("Failed to find the primary service of sidekick %s", s.name)
+}
+
+func (s *Sidekick) Info(ctx context.Context) (project.InfoSet, error) {
+	result := project.InfoSet{}
+	for _, primary := range s.primaries() {
+		serviceConfig, ok := s.context.Project.ServiceConfigs.Get(primary)
+		if !ok {
+			continue
+		}
+
+		info, err := NewService(primary, serviceConfig, s.context).info(s.name)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, containerInfo := range info {
+			containerInfo["Service"] = s.name
+		}
+		result = append(result, info...)
+	}
+	return result, nil
+}
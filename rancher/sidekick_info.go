@@ -3,9 +3,15 @@ package rancher
 import (
 	"strings"
 
+	"github.com/rancher/go-rancher/v2"
 	"github.com/rancher/rancher-compose-executor/project"
 )
 
+const (
+	launchConfigLabel   = "io.rancher.service.launch.config"
+	primaryLaunchConfig = "io.rancher.service.primary.launch.config"
+)
+
 type SidekickInfo struct {
 	primariesToSidekicks map[string][]string
 	primaries            map[string]bool
@@ -48,3 +54,12 @@ func NewSidekickInfo(project *project.Project) *SidekickInfo {
 
 	return result
 }
+
+func isLaunchConfig(container *client.Container, launchConfig string) bool {
+	value, ok := container.Labels[launchConfigLabel]
+	if !ok {
+		// Containers without the label can only come from the primary launch config
+		return launchConfig == primaryLaunchConfig
+	}
+	return value == launchConfig
+}
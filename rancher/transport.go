@@ -0,0 +1,197 @@
+package rancher
+
+import (
+	"bufio"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/rancher/go-rancher/v2"
+)
+
+const defaultAPITimeout = 10 * time.Second
+
+// transport returns the transport of the requests to the Rancher API with the
+// TLS and proxy settings of the context, nil leaves the default transport of
+// net/http in use when there are none. The settings only apply to the clients
+// of the context, never to the rest of the process.
+func (c *Context) transport() (http.RoundTripper, error) {
+	if c.CACert == "" && !c.InsecureSkipVerify && c.Proxy == "" {
+		return nil, nil
+	}
+
+	tlsConfig, err := c.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+	proxy, err := c.proxy()
+	if err != nil {
+		return nil, err
+	}
+
+	c.tls = tlsConfig
+	return &http.Transport{
+		Proxy: proxy,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		TLSClientConfig:       tlsConfig,
+		TLSHandshakeTimeout:   10 * time.Second,
+		IdleConnTimeout:       90 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+		MaxIdleConns:          100,
+	}, nil
+}
+
+func (c *Context) tlsConfig() (*tls.Config, error) {
+	if c.CACert == "" && !c.InsecureSkipVerify {
+		return nil, nil
+	}
+
+	config := &tls.Config{
+		InsecureSkipVerify: c.InsecureSkipVerify,
+	}
+
+	if c.CACert != "" {
+		pem, err := ioutil.ReadFile(c.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read CA certificates: %v", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("No PEM certificate found in %s", c.CACert)
+		}
+		config.RootCAs = pool
+	}
+
+	return config, nil
+}
+
+// proxy returns the proxy of the API, HTTPS_PROXY, HTTP_PROXY and NO_PROXY
+// are used when none is set.
+func (c *Context) proxy() (func(*http.Request) (*url.URL, error), error) {
+	if c.Proxy == "" {
+		return http.ProxyFromEnvironment, nil
+	}
+
+	proxyURL, err := url.Parse(c.Proxy)
+	if err != nil || proxyURL.Host == "" {
+		return nil, fmt.Errorf("Invalid proxy URL %s", c.Proxy)
+	}
+	return http.ProxyURL(proxyURL), nil
+}
+
+func (c *Context) apiTimeout() time.Duration {
+	if c.APITimeout > 0 {
+		return c.APITimeout
+	}
+	return defaultAPITimeout
+}
+
+// hostAccess runs a host access action, like logs or execute, and opens the
+// websocket it returns with the TLS and proxy settings of the context.
+func (c *Context) hostAccess(resource client.Resource, action string, input interface{}) (*websocket.Conn, error) {
+	url := resource.Actions[action]
+	if url == "" {
+		return nil, fmt.Errorf("Failed to find action: %s", action)
+	}
+
+	var resp client.HostAccess
+	if err := c.Client.Post(url, input, &resp); err != nil {
+		return nil, err
+	}
+
+	return c.dialWebsocket(fmt.Sprintf("%s?token=%s", resp.Url, resp.Token))
+}
+
+func (c *Context) dialWebsocket(rawurl string) (*websocket.Conn, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := &websocket.Dialer{
+		TLSClientConfig:  c.tls,
+		HandshakeTimeout: c.apiTimeout(),
+	}
+
+	proxy, err := c.proxy()
+	if err != nil {
+		return nil, err
+	}
+	scheme := "http"
+	if u.Scheme == "wss" {
+		scheme = "https"
+	}
+	proxyURL, err := proxy(&http.Request{URL: &url.URL{Scheme: scheme, Host: u.Host}})
+	if err != nil {
+		return nil, err
+	}
+	if proxyURL != nil {
+		timeout := c.apiTimeout()
+		dialer.NetDial = func(network, addr string) (net.Conn, error) {
+			return dialProxy(proxyURL, addr, timeout)
+		}
+	}
+
+	conn, _, err := dialer.Dial(rawurl, nil)
+	return conn, err
+}
+
+// dialProxy opens a tunnel to addr through an HTTP proxy.
+func dialProxy(proxyURL *url.URL, addr string, timeout time.Duration) (net.Conn, error) {
+	proxyAddr := proxyURL.Host
+	if proxyURL.Port() == "" {
+		proxyAddr = net.JoinHostPort(proxyURL.Hostname(), "80")
+		if proxyURL.Scheme == "https" {
+			proxyAddr = net.JoinHostPort(proxyURL.Hostname(), "443")
+		}
+	}
+
+	conn, err := net.DialTimeout("tcp", proxyAddr, timeout)
+	if err != nil {
+		return nil, err
+	}
+	if proxyURL.Scheme == "https" {
+		conn = tls.Client(conn, &tls.Config{ServerName: proxyURL.Hostname()})
+	}
+
+	req := &http.Request{
+		Method: "CONNECT",
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: http.Header{},
+	}
+	if proxyURL.User != nil {
+		password, _ := proxyURL.User.Password()
+		credentials := base64.StdEncoding.EncodeToString([]byte(proxyURL.User.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+credentials)
+	}
+
+	conn.SetDeadline(time.Now().Add(timeout))
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	resp, err := http.ReadResponse(bufio.NewReader(conn), req)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("Proxy %s refused to connect to %s: %s", proxyURL.Host, addr, resp.Status)
+	}
+	conn.SetDeadline(time.Time{})
+
+	return conn, nil
+}
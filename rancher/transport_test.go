@@ -0,0 +1,178 @@
+package rancher
+
+import (
+	"encoding/pem"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTLSServer() *httptest.Server {
+	var server *httptest.Server
+	server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/ws" {
+			conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+			if err != nil {
+				return
+			}
+			conn.WriteMessage(websocket.TextMessage, []byte("hello"))
+			conn.Close()
+			return
+		}
+		w.Header().Set("X-API-Schemas", server.URL+"/v2-beta")
+		w.Write([]byte(`{"type": "collection", "data": [{"id": "stack", "collectionMethods": ["GET", "POST"]}]}`))
+	}))
+	return server
+}
+
+func writeCACert(t *testing.T, server *httptest.Server) string {
+	file, err := ioutil.TempFile("", "ca")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	pem.Encode(file, &pem.Block{Type: "CERTIFICATE", Bytes: server.TLS.Certificates[0].Certificate[0]})
+	return file.Name()
+}
+
+func TestLoadClientTLS(t *testing.T) {
+	server := newTLSServer()
+	defer server.Close()
+	caCert := writeCACert(t, server)
+	defer os.Remove(caCert)
+
+	_, err := (&Context{Url: server.URL}).loadClient()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "certificate")
+
+	c := &Context{Url: server.URL, CACert: caCert}
+	_, err = c.loadClient()
+	assert.NoError(t, err)
+	_, ok := c.Client.GetTypes()["stack"]
+	assert.True(t, ok)
+
+	_, err = (&Context{Url: server.URL, InsecureSkipVerify: true}).loadClient()
+	assert.NoError(t, err)
+
+	_, err = (&Context{Url: server.URL, CACert: os.DevNull}).loadClient()
+	assert.EqualError(t, err, "No PEM certificate found in "+os.DevNull)
+}
+
+func TestDialWebsocketTLS(t *testing.T) {
+	server := newTLSServer()
+	defer server.Close()
+	caCert := writeCACert(t, server)
+	defer os.Remove(caCert)
+
+	url := strings.Replace(server.URL, "https://", "wss://", 1) + "/ws"
+
+	c := &Context{}
+	_, err := c.dialWebsocket(url)
+	assert.Error(t, err)
+
+	c = &Context{CACert: caCert}
+	_, err = c.transport()
+	assert.NoError(t, err)
+	conn, err := c.dialWebsocket(url)
+	if assert.NoError(t, err) {
+		_, message, err := conn.ReadMessage()
+		assert.NoError(t, err)
+		assert.Equal(t, "hello", string(message))
+		conn.Close()
+	}
+}
+
+func TestDialWebsocketProxy(t *testing.T) {
+	server := newTLSServer()
+	defer server.Close()
+	caCert := writeCACert(t, server)
+	defer os.Remove(caCert)
+
+	var tunnels int32
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "CONNECT" || r.Header.Get("Proxy-Authorization") != "Basic dXNlcjpwYXNz" {
+			w.WriteHeader(http.StatusProxyAuthRequired)
+			return
+		}
+		target, err := net.Dial("tcp", r.Host)
+		if err != nil {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		atomic.AddInt32(&tunnels, 1)
+		conn, buffer, _ := w.(http.Hijacker).Hijack()
+		conn.Write([]byte("HTTP/1.1 200 OK\r\n\r\n"))
+		go io.Copy(target, buffer)
+		io.Copy(conn, target)
+		conn.Close()
+	}))
+	defer proxy.Close()
+
+	url := strings.Replace(server.URL, "https://", "wss://", 1) + "/ws"
+	proxyURL := strings.Replace(proxy.URL, "http://", "http://user:pass@", 1)
+
+	c := &Context{CACert: caCert, Proxy: proxyURL}
+	_, err := c.transport()
+	assert.NoError(t, err)
+	conn, err := c.dialWebsocket(url)
+	if assert.NoError(t, err) {
+		_, message, err := conn.ReadMessage()
+		assert.NoError(t, err)
+		assert.Equal(t, "hello", string(message))
+		conn.Close()
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&tunnels))
+
+	c = &Context{CACert: caCert, Proxy: proxy.URL}
+	_, err = c.transport()
+	assert.NoError(t, err)
+	_, err = c.dialWebsocket(url)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "407")
+}
+
+func TestTransportOtherHost(t *testing.T) {
+	defaultTransport := http.DefaultTransport
+	ok := func(w http.ResponseWriter, r *http.Request) {}
+	api := httptest.NewServer(http.HandlerFunc(ok))
+	defer api.Close()
+	other := httptest.NewServer(http.HandlerFunc(ok))
+	defer other.Close()
+
+	var proxied int32
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&proxied, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer proxy.Close()
+
+	c := &Context{Url: api.URL + "/v2-beta", Proxy: proxy.URL}
+	transport, err := c.transport()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	resp, err := (&http.Client{Transport: transport}).Get(api.URL)
+	if assert.NoError(t, err) {
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&proxied))
+
+	resp, err = http.Get(other.URL)
+	if assert.NoError(t, err) {
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&proxied))
+	assert.True(t, defaultTransport == http.DefaultTransport, "the default transport was changed")
+}
@@ -1,31 +1,33 @@
 package rancher
 
 import (
+	"golang.org/x/net/context"
+
 	"github.com/Sirupsen/logrus"
 	"github.com/rancher/go-rancher/v2"
 	"github.com/rancher/rancher-compose-executor/digest"
 )
 
-func (r *RancherService) upgrade(service *client.Service, force bool, selected []string) (*client.Service, error) {
+func (r *RancherService) upgrade(ctx context.Context, service *client.Service, force bool, selected []string) (*client.Service, error) {
 	factory, err := GetFactory(r)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := factory.Upgrade(r, force, selected); err != nil {
+	if err := factory.Upgrade(ctx, r, force, selected); err != nil {
 		return nil, err
 	}
 
 	return r.FindExisting(r.name)
 }
 
-func (r *RancherService) rollback(service *client.Service) (*client.Service, error) {
+func (r *RancherService) rollback(ctx context.Context, service *client.Service) (*client.Service, error) {
 	factory, err := GetFactory(r)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := factory.Rollback(r); err != nil {
+	if err := factory.Rollback(ctx, r); err != nil {
 		return nil, err
 	}
 
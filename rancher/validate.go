@@ -0,0 +1,109 @@
+package rancher
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/rancher/rancher-compose-executor/config"
+	"github.com/rancher/rancher-compose-executor/project"
+)
+
+// Validate checks that the services of a project only refer to services that
+// are defined and that their dependencies have no cycle. Every problem found
+// is returned, prefixed with the files defining the services involved.
+func Validate(p *project.Project) ([]string, error) {
+	problems := []string{}
+
+	defined := func(name string) bool {
+		return p.ServiceConfigs.Has(name) || p.ContainerConfigs.Has(name)
+	}
+
+	names := p.ServiceConfigs.Keys()
+	sort.Strings(names)
+
+	for _, name := range names {
+		serviceConfig, _ := p.ServiceConfigs.Get(name)
+		for _, problem := range validateReferences(name, serviceConfig, defined) {
+			problems = append(problems, withFiles(p, []string{name}, problem))
+		}
+	}
+
+	cycles, err := p.Cycles()
+	if err != nil {
+		return nil, err
+	}
+	for _, cycle := range cycles {
+		problems = append(problems, withFiles(p, strings.Split(cycle, "->"), fmt.Sprintf("Cycle detected in path %s", cycle)))
+	}
+
+	return problems, nil
+}
+
+// withFiles prefixes a problem with the files that define the services, like
+// the schema problems of a file are.
+func withFiles(p *project.Project, services []string, problem string) string {
+	files := []string{}
+	seen := map[string]bool{}
+	for _, service := range services {
+		for _, file := range p.ServiceFiles[service] {
+			if file != "" && !seen[file] {
+				seen[file] = true
+				files = append(files, file)
+			}
+		}
+	}
+
+	if len(files) == 0 {
+		return problem
+	}
+	return fmt.Sprintf("%s: %s", strings.Join(files, ", "), problem)
+}
+
+func validateReferences(name string, serviceConfig *config.ServiceConfig, defined func(string) bool) []string {
+	problems := []string{}
+
+	for _, link := range serviceConfig.Links {
+		target, _ := project.NameAlias(link)
+		if !defined(target) {
+			problems = append(problems, fmt.Sprintf("Service '%s' has a link to service '%s' which is undefined", name, target))
+		}
+	}
+
+	for _, sidekick := range strings.Split(serviceConfig.Labels["io.rancher.sidekicks"], ",") {
+		sidekick = strings.TrimSpace(sidekick)
+		if sidekick != "" && !defined(sidekick) {
+			problems = append(problems, fmt.Sprintf("Service '%s' has a sidekick '%s' which is undefined", name, sidekick))
+		}
+	}
+
+	for _, volumesFrom := range serviceConfig.VolumesFrom {
+		parts := strings.Split(volumesFrom, ":")
+		if parts[0] == "container" {
+			continue
+		}
+		if !defined(parts[0]) {
+			problems = append(problems, fmt.Sprintf("Service '%s' mounts volumes from service '%s' which is undefined", name, parts[0]))
+		}
+	}
+
+	if strings.HasPrefix(serviceConfig.NetworkMode, "service:") {
+		target := strings.TrimPrefix(serviceConfig.NetworkMode, "service:")
+		if !defined(target) {
+			problems = append(problems, fmt.Sprintf("Service '%s' uses the network of service '%s' which is undefined", name, target))
+		}
+	}
+
+	if serviceConfig.LbConfig != nil {
+		for _, portRule := range serviceConfig.LbConfig.PortRules {
+			if portRule.Service == "" || strings.Contains(portRule.Service, "/") {
+				continue
+			}
+			if !defined(portRule.Service) {
+				problems = append(problems, fmt.Sprintf("Load balancer '%s' has a port rule for service '%s' which is undefined", name, portRule.Service))
+			}
+		}
+	}
+
+	return problems
+}
@@ -0,0 +1,93 @@
+package rancher
+
+import (
+	"testing"
+
+	"github.com/rancher/rancher-compose-executor/lookup"
+	"github.com/rancher/rancher-compose-executor/project"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	context := &Context{
+		Context: project.Context{
+			ProjectName:       "test",
+			ComposeFiles:      []string{"docker-compose.yml"},
+			EnvironmentLookup: &lookup.MapEnvLookup{},
+			ComposeBytes: [][]byte{[]byte(`
+version: '2'
+services:
+  web:
+    image: nginx
+    links:
+    - missing
+    labels:
+      io.rancher.sidekicks: helper
+    volumes_from:
+    - helper
+  helper:
+    image: busybox
+  lb1:
+    image: rancher/lb-service-haproxy
+    lb_config:
+      port_rules:
+      - source_port: 80
+        target_port: 80
+        service: lb2
+  lb2:
+    image: rancher/lb-service-haproxy
+    lb_config:
+      port_rules:
+      - source_port: 80
+        target_port: 80
+        service: lb1
+      - source_port: 81
+        target_port: 80
+        service: other/web
+`)},
+		},
+	}
+
+	p, err := NewOfflineProject(context)
+	assert.Nil(t, err)
+
+	problems, err := Validate(p)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"docker-compose.yml: Service 'web' has a link to service 'missing' which is undefined",
+		"docker-compose.yml: Cycle detected in path lb1->lb2->lb1",
+	}, problems)
+}
+
+func TestValidateFiles(t *testing.T) {
+	context := &Context{
+		Context: project.Context{
+			ProjectName:       "test",
+			ComposeFiles:      []string{"base.yml", "override.yml"},
+			EnvironmentLookup: &lookup.MapEnvLookup{},
+			ComposeBytes: [][]byte{[]byte(`
+version: '2'
+services:
+  web:
+    image: nginx
+  db:
+    image: mysql
+`), []byte(`
+version: '2'
+services:
+  web:
+    links:
+    - missing
+`)},
+		},
+	}
+
+	p, err := NewOfflineProject(context)
+	assert.Nil(t, err)
+
+	problems, err := Validate(p)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"base.yml, override.yml: Service 'web' has a link to service 'missing' which is undefined",
+	}, problems)
+}
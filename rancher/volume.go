@@ -86,12 +86,42 @@ func (v *Volume) Remove(ctx context.Context) error {
 	}
 
 	volumeResource, err := v.Inspect(ctx)
-	if err != nil {
+	if err != nil || volumeResource == nil {
 		return err
 	}
+
+	if v.perContainer {
+		if err := v.removeContainerVolumes(volumeResource); err != nil {
+			return err
+		}
+	}
+
+	logrus.Infof("Removing volume template %s", v.name)
 	return v.context.Client.VolumeTemplate.Delete(volumeResource)
 }
 
+// removeContainerVolumes removes the volumes created for each container from
+// a per container template.
+func (v *Volume) removeContainerVolumes(template *client.VolumeTemplate) error {
+	volumes, err := v.context.Client.Volume.List(&client.ListOpts{
+		Filters: map[string]interface{}{
+			"volumeTemplateId": template.Id,
+			"removed_null":     nil,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, volume := range volumes.Data {
+		logrus.Infof("Removing volume %s of volume template %s", volume.Name, v.name)
+		if err := v.context.Client.Volume.Delete(&volume); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (v *Volume) EnsureItExists(ctx context.Context) error {
 	volumeResource, err := v.Inspect(ctx)
 	if err != nil {
@@ -0,0 +1,88 @@
+package rancher
+
+import (
+	"sort"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/rancher/go-rancher/v2"
+	"github.com/rancher/rancher-compose-executor/config"
+	"github.com/rancher/rancher-compose-executor/yaml"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeVolumeTemplates struct {
+	client.VolumeTemplateOperations
+	templates []client.VolumeTemplate
+	deleted   []string
+}
+
+func (f *fakeVolumeTemplates) List(opts *client.ListOpts) (*client.VolumeTemplateCollection, error) {
+	collection := &client.VolumeTemplateCollection{}
+	for _, template := range f.templates {
+		if template.Name == opts.Filters["name"] && template.StackId == opts.Filters["stackId"] {
+			collection.Data = append(collection.Data, template)
+		}
+	}
+	return collection, nil
+}
+
+func (f *fakeVolumeTemplates) Delete(template *client.VolumeTemplate) error {
+	f.deleted = append(f.deleted, template.Name)
+	return nil
+}
+
+type fakeVolumes struct {
+	client.VolumeOperations
+	volumes []client.Volume
+	deleted []string
+}
+
+func (f *fakeVolumes) List(opts *client.ListOpts) (*client.VolumeCollection, error) {
+	collection := &client.VolumeCollection{}
+	for _, volume := range f.volumes {
+		if volume.VolumeTemplateId == opts.Filters["volumeTemplateId"] {
+			collection.Data = append(collection.Data, volume)
+		}
+	}
+	return collection, nil
+}
+
+func (f *fakeVolumes) Delete(volume *client.Volume) error {
+	f.deleted = append(f.deleted, volume.Name)
+	return nil
+}
+
+func TestRemoveVolumes(t *testing.T) {
+	templates := &fakeVolumeTemplates{templates: []client.VolumeTemplate{
+		{Resource: client.Resource{Id: "1vt1"}, Name: "data", StackId: "1st1", PerContainer: true},
+		{Resource: client.Resource{Id: "1vt2"}, Name: "logs", StackId: "1st1"},
+		{Resource: client.Resource{Id: "1vt3"}, Name: "shared", StackId: "1st1", External: true},
+	}}
+	volumes := &fakeVolumes{volumes: []client.Volume{
+		{Name: "web_data_1", VolumeTemplateId: "1vt1"},
+		{Name: "web_data_2", VolumeTemplateId: "1vt1"},
+		{Name: "shared", VolumeTemplateId: "1vt3"},
+	}}
+	c := &Context{
+		Client: &client.RancherClient{VolumeTemplate: templates, Volume: volumes},
+		Stack:  &client.Stack{Resource: client.Resource{Id: "1st1"}},
+	}
+
+	factory := &RancherVolumesFactory{Context: c}
+	v, err := factory.Create("web", map[string]*config.VolumeConfig{
+		"data":   {PerContainer: true},
+		"logs":   {},
+		"shared": {External: yaml.External{External: true}},
+	}, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.NoError(t, v.Remove(context.Background()))
+	sort.Strings(templates.deleted)
+	sort.Strings(volumes.deleted)
+	assert.Equal(t, []string{"data", "logs"}, templates.deleted)
+	assert.Equal(t, []string{"web_data_1", "web_data_2"}, volumes.deleted)
+}
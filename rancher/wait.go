@@ -3,16 +3,22 @@ package rancher
 import (
 	"time"
 
+	"golang.org/x/net/context"
+
 	"github.com/rancher/go-rancher/v2"
 )
 
-func (r *RancherService) WaitFor(resource *client.Resource, output interface{}, transitioning func() string) error {
+func (r *RancherService) WaitFor(ctx context.Context, resource *client.Resource, output interface{}, transitioning func() string) error {
 	for {
 		if transitioning() != "yes" {
 			return nil
 		}
 
-		time.Sleep(150 * time.Millisecond)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(150 * time.Millisecond):
+		}
 
 		err := r.context.Client.Reload(resource, output)
 		if err != nil {
@@ -21,14 +27,14 @@ func (r *RancherService) WaitFor(resource *client.Resource, output interface{},
 	}
 }
 
-func (r *RancherService) Wait(service *client.Service) error {
-	return r.WaitFor(&service.Resource, service, func() string {
+func (r *RancherService) Wait(ctx context.Context, service *client.Service) error {
+	return r.WaitFor(ctx, &service.Resource, service, func() string {
 		return service.Transitioning
 	})
 }
 
-func (r *RancherService) waitInstance(instance *client.Instance) error {
-	return r.WaitFor(&instance.Resource, instance, func() string {
+func (r *RancherService) waitInstance(ctx context.Context, instance *client.Instance) error {
+	return r.WaitFor(ctx, &instance.Resource, instance, func() string {
 		return instance.Transitioning
 	})
 }